@@ -0,0 +1,185 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// defaultPriorPrecision is the precision of the coefficients under the default, nearly flat, prior.
+const defaultPriorPrecision = 1e-6
+
+// NIGPrior is the conjugate normal-inverse-gamma prior of a Bayesian linear regression: the variance
+// of the errors σ² follows an inverse gamma distribution of parameters Shape and Scale, and given σ²,
+// the coefficients follow a normal distribution of mean Mean and covariance σ² Precision⁻¹. The zero
+// value is a nearly flat prior, under which the posterior mean is the least squares fit.
+type NIGPrior struct {
+	Mean      []float64     // prior mean of the coefficients, in the order of GetCoeffs, zero if nil
+	Precision mat.Symmetric // prior precision of the coefficients relative to σ², 1e-6 I if nil
+	Shape     float64       // shape of the inverse gamma prior of σ²
+	Scale     float64       // scale of the inverse gamma prior of σ²
+}
+
+// Bayesian is a Bayesian linear regression with a conjugate normal-inverse-gamma prior. Rather than
+// point estimates, it provides the posterior distribution of the coefficients and the predictive
+// distribution of new observations, which quantify the uncertainty of the fit.
+type Bayesian struct {
+	Data  []DataPoint
+	Prior NIGPrior
+	base  Regression // holds the feature crosses
+
+	numVars   int
+	mean      *mat.VecDense // μₙ
+	precision *mat.SymDense // Λₙ
+	cov       *mat.SymDense // Λₙ⁻¹
+	shape     float64       // aₙ
+	scale     float64       // bₙ
+}
+
+// Train adds training data points.
+func (b *Bayesian) Train(d ...DataPoint) {
+	b.Data = append(b.Data, d...)
+}
+
+// AddCross registers a feature cross to be applied to the data points.
+func (b *Bayesian) AddCross(cross featureCross) {
+	b.base.AddCross(cross)
+}
+
+// Run computes the posterior distribution of the coefficients and of the variance of the errors:
+//
+//	Λₙ = XᵀX + Λ₀, μₙ = Λₙ⁻¹(Λ₀μ₀ + Xᵀy), aₙ = a₀ + n/2, bₙ = b₀ + (yᵀy + μ₀ᵀΛ₀μ₀ - μₙᵀΛₙμₙ)/2
+//
+// The data points are weighted by their Weight.
+func (b *Bayesian) Run() error {
+	if len(b.Data) == 0 {
+		return ErrNotEnoughData
+	}
+	if err := DataPoints(b.Data).Validate(); err != nil {
+		return err
+	}
+	p := len(b.base.features(b.Data[0].Variables)) + 1
+
+	prior := mat.NewSymDense(p, nil)
+	if b.Prior.Precision != nil {
+		if b.Prior.Precision.SymmetricDim() != p {
+			return ErrVariableCount
+		}
+		prior.CopySym(b.Prior.Precision)
+	} else {
+		for j := 0; j < p; j++ {
+			prior.SetSym(j, j, defaultPriorPrecision)
+		}
+	}
+	priorMean := mat.NewVecDense(p, nil)
+	if b.Prior.Mean != nil {
+		if len(b.Prior.Mean) != p {
+			return ErrVariableCount
+		}
+		priorMean.CopyVec(mat.NewVecDense(p, b.Prior.Mean))
+	}
+	if b.Prior.Shape < 0 || b.Prior.Scale < 0 {
+		return ErrInvalidPrior
+	}
+
+	precision := mat.NewSymDense(p, nil)
+	precision.CopySym(prior)
+	xty := mat.NewVecDense(p, nil)
+	xty.MulVec(prior, priorMean)
+	// μ₀ᵀΛ₀μ₀, then the weighted yᵀy
+	quadratic := mat.Dot(priorMean, xty)
+	var weights float64
+	for _, d := range b.Data {
+		row := mat.NewVecDense(p, append([]float64{1}, b.base.features(d.Variables)...))
+		w := d.weight()
+		precision.SymRankOne(precision, w, row)
+		xty.AddScaledVec(xty, w*d.Observed, row)
+		quadratic += w * d.Observed * d.Observed
+		weights += w
+	}
+
+	var chol mat.Cholesky
+	if !chol.Factorize(precision) {
+		return ErrSingularMatrix
+	}
+	mean := mat.NewVecDense(p, nil)
+	if err := chol.SolveVecTo(mean, xty); err != nil {
+		return ErrSingularMatrix
+	}
+	cov := new(mat.SymDense)
+	if err := chol.InverseTo(cov); err != nil {
+		return ErrSingularMatrix
+	}
+
+	b.numVars = len(b.Data[0].Variables)
+	b.mean, b.precision, b.cov = mean, precision, cov
+	b.shape = b.Prior.Shape + weights/2
+	// μₙᵀΛₙμₙ = μₙᵀ(Λ₀μ₀ + Xᵀy)
+	b.scale = b.Prior.Scale + math.Max(quadratic-mat.Dot(mean, xty), 0)/2
+	return nil
+}
+
+// PosteriorMean returns the posterior mean of the coefficients, in the order of GetCoeffs.
+func (b *Bayesian) PosteriorMean() ([]float64, error) {
+	if b.mean == nil {
+		return nil, ErrRegressionRun
+	}
+	return mat.Col(nil, 0, b.mean), nil
+}
+
+// PosteriorCovariance returns the covariance matrix of the marginal posterior distribution of the
+// coefficients, bₙ/(aₙ-1) Λₙ⁻¹, which requires aₙ > 1.
+func (b *Bayesian) PosteriorCovariance() (*mat.SymDense, error) {
+	if b.mean == nil {
+		return nil, ErrRegressionRun
+	}
+	if b.shape <= 1 {
+		return nil, ErrNotEnoughData
+	}
+	cov := new(mat.SymDense)
+	cov.ScaleSym(b.scale/(b.shape-1), b.cov)
+	return cov, nil
+}
+
+// CredibleInterval returns the central credible interval of coefficient i with the given probability,
+// e.g. 0.95. The marginal posterior of a coefficient is a Student's t distribution with 2aₙ degrees of
+// freedom.
+func (b *Bayesian) CredibleInterval(i int, probability float64) (lower, upper float64, err error) {
+	if b.mean == nil {
+		return 0, 0, ErrRegressionRun
+	}
+	if i < 0 || i >= b.mean.Len() {
+		return 0, 0, ErrInvalidIndex
+	}
+	if probability <= 0 || probability >= 1 {
+		return 0, 0, ErrInvalidProbability
+	}
+	t := distuv.StudentsT{
+		Mu:    b.mean.AtVec(i),
+		Sigma: math.Sqrt(b.scale / b.shape * b.cov.At(i, i)),
+		Nu:    2 * b.shape,
+	}
+	return t.Quantile((1 - probability) / 2), t.Quantile((1 + probability) / 2), nil
+}
+
+// Predict returns the posterior predictive distribution of the observed value for the inputed
+// features, a Student's t distribution with 2aₙ degrees of freedom, centered on xᵀμₙ and of scale
+// sqrt(bₙ/aₙ (1 + xᵀΛₙ⁻¹x)), which accounts both for the noise and for the uncertainty of the
+// coefficients.
+func (b *Bayesian) Predict(vars []float64) (distuv.StudentsT, error) {
+	if b.mean == nil {
+		return distuv.StudentsT{}, ErrRegressionRun
+	}
+	if len(vars) != b.numVars {
+		return distuv.StudentsT{}, ErrVariableCount
+	}
+	x := mat.NewVecDense(b.mean.Len(), append([]float64{1}, b.base.features(vars)...))
+	tmp := mat.NewVecDense(x.Len(), nil)
+	tmp.MulVec(b.cov, x)
+	return distuv.StudentsT{
+		Mu:    mat.Dot(x, b.mean),
+		Sigma: math.Sqrt(b.scale / b.shape * (1 + mat.Dot(x, tmp))),
+		Nu:    2 * b.shape,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBayesian(t *testing.T) {
+	b := &Bayesian{}
+	if _, err := b.Predict([]float64{1}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		x := rnd.Float64() * 10
+		b.Train(DataPoint{Observed: 1 + 2*x + 0.5*rnd.NormFloat64(), Variables: []float64{x}})
+	}
+	if err := b.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// under the default flat prior, the posterior mean is the least squares fit
+	r := &Regression{}
+	r.Train(b.Data...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	mean, err := b.PosteriorMean()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(mean[i]-c) > 1e-6 {
+			t.Errorf("Expected the least squares coefficients %v, got %v", r.GetCoeffs(), mean)
+			break
+		}
+	}
+
+	lower, upper, err := b.CredibleInterval(1, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lower > 2 || upper < 2 || upper-lower > 0.2 {
+		t.Errorf("Expected a narrow interval around 2, got [%v, %v]", lower, upper)
+	}
+	cov, err := b.PosteriorCovariance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cov.At(0, 1) >= 0 {
+		t.Errorf("Expected the offset and the slope to be negatively correlated, got %v", cov.At(0, 1))
+	}
+
+	predictive, err := b.Predict([]float64{5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(predictive.Mu-11) > 0.2 || math.Abs(predictive.StdDev()-0.5) > 0.1 {
+		t.Errorf("Expected a predictive distribution close to N(11, 0.5), got %v ± %v", predictive.Mu, predictive.StdDev())
+	}
+	far, _ := b.Predict([]float64{100})
+	if far.Sigma <= predictive.Sigma {
+		t.Errorf("Expected a wider predictive distribution away from the data, got %v and %v", far.Sigma, predictive.Sigma)
+	}
+
+	// a strong prior on a zero slope shrinks it
+	b.Prior = NIGPrior{Precision: mat.NewSymDense(2, []float64{1e-6, 0, 0, 1e6})}
+	if err := b.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if mean, _ := b.PosteriorMean(); math.Abs(mean[1]) > 0.01 {
+		t.Errorf("Expected the slope to be shrunk to 0, got %v", mean)
+	}
+}
@@ -0,0 +1,199 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// bound is the range allowed for a coefficient by SetBound.
+type bound struct {
+	lower, upper float64
+}
+
+// SetBound constrains coefficient i to lie between lower and upper, either of which can be infinite,
+// e.g. SetBound(2, 0, math.Inf(1)) for a non-negative coefficient. Index 0 is the offset, and the
+// following indices are the variables then the feature crosses. Run then solves the bounded least
+// squares problem by projected coordinate descent. Bounds can't be combined with fixed or non-negative
+// coefficients, regularization, quantiles, distributions, nor with Accumulate.
+func (r *Regression) SetBound(i int, lower, upper float64) {
+	if r.bounds == nil {
+		r.bounds = map[int]bound{}
+	}
+	r.bounds[i] = bound{lower: lower, upper: upper}
+}
+
+// checkBounds validates the bounds of the coefficients, whose indices are checked by Run once the
+// number of features is known.
+func (r *Regression) checkBounds() error {
+	if len(r.bounds) == 0 {
+		return nil
+	}
+	if len(r.fixed) > 0 || r.nonNegative || r.penalty != NoRegularization || r.quantileFit || r.family != nil {
+		return ErrUnsupportedFit
+	}
+	for _, b := range r.bounds {
+		if b.lower > b.upper || math.IsNaN(b.lower) || math.IsNaN(b.upper) {
+			return ErrInvalidBound
+		}
+	}
+	return nil
+}
+
+// solveBounded returns the least squares coefficients within their bounds, starting from the
+// unconstrained solution clipped to the bounds, then minimising over each coefficient in turn.
+func solveBounded(variables, observed *mat.Dense, weights []float64, bounds map[int]bound) []float64 {
+	if weights != nil {
+		variables, observed = weightRows(variables, weights), weightRows(observed, weights)
+	}
+	n, p := variables.Dims()
+	clip := func(j int, val float64) float64 {
+		if b, ok := bounds[j]; ok {
+			return math.Max(b.lower, math.Min(b.upper, val))
+		}
+		return val
+	}
+
+	c := solveLeastSquares(variables, observed)
+	for j := range c {
+		c[j] = clip(j, c[j])
+	}
+	residuals := mat.Col(nil, 0, observed)
+	norms := make([]float64, p)
+	for i := 0; i < n; i++ {
+		for j := 0; j < p; j++ {
+			residuals[i] -= c[j] * variables.At(i, j)
+			norms[j] += variables.At(i, j) * variables.At(i, j)
+		}
+	}
+
+	for sweep := 0; sweep < maxDescentSweeps; sweep++ {
+		var change float64
+		for j := 0; j < p; j++ {
+			if norms[j] == 0 {
+				continue
+			}
+			rho := norms[j] * c[j]
+			for i := 0; i < n; i++ {
+				rho += variables.At(i, j) * residuals[i]
+			}
+			updated := clip(j, rho/norms[j])
+			if delta := updated - c[j]; delta != 0 {
+				for i := 0; i < n; i++ {
+					residuals[i] -= delta * variables.At(i, j)
+				}
+				change = math.Max(change, delta*delta*norms[j])
+				c[j] = updated
+			}
+		}
+		if change <= descentTolerance {
+			break
+		}
+	}
+	return c
+}
+
+// solveNonNegative returns the least squares coefficients, constrained to be non-negative, except for
+// the offset unless constrainOffset is set. The first column of variables must be the column of ones.
+func solveNonNegative(variables, observed *mat.Dense, weights []float64, constrainOffset bool) []float64 {
+	if constrainOffset {
+		if weights != nil {
+			variables, observed = weightRows(variables, weights), weightRows(observed, weights)
+		}
+		return nnls(variables, observed)
+	}
+	// the offset is unconstrained, so it can be projected out by centering
+	centered, centeredObserved, means, obmean := center(variables, observed, weights)
+	if weights != nil {
+		centered, centeredObserved = weightRows(centered, weights), weightRows(centeredObserved, weights)
+	}
+	return withOffset(nnls(centered, centeredObserved), means, obmean)
+}
+
+// nnls solves min ||Ax - b|| subject to x >= 0 with the Lawson-Hanson active set algorithm.
+func nnls(a, b *mat.Dense) []float64 {
+	_, p := a.Dims()
+	x := make([]float64, p)
+	passive := make([]bool, p)
+	const tol = 1e-10
+
+	gradient := func() []float64 {
+		residual := mat.DenseCopyOf(b)
+		fitted := new(mat.Dense)
+		fitted.Mul(a, mat.NewDense(p, 1, x))
+		residual.Sub(residual, fitted)
+		w := new(mat.Dense)
+		w.Mul(a.T(), residual)
+		return mat.Col(nil, 0, w)
+	}
+
+	for iter := 0; iter < 3*p; iter++ {
+		// free the variable which would decrease the residuals the most
+		w := gradient()
+		best := -1
+		for j := range w {
+			if !passive[j] && w[j] > tol && (best < 0 || w[j] > w[best]) {
+				best = j
+			}
+		}
+		if best < 0 {
+			break
+		}
+		passive[best] = true
+
+		for {
+			s := solveSubset(a, b, passive)
+			feasible := true
+			alpha := math.Inf(1)
+			for j := range s {
+				if passive[j] && s[j] <= tol {
+					feasible = false
+					if d := x[j] - s[j]; d > 0 {
+						alpha = math.Min(alpha, x[j]/d)
+					} else {
+						alpha = 0
+					}
+				}
+			}
+			if feasible {
+				x = s
+				break
+			}
+			// move towards s until a variable hits zero, and constrain it
+			for j := range x {
+				x[j] += alpha * (s[j] - x[j])
+				if passive[j] && x[j] <= tol {
+					passive[j] = false
+					x[j] = 0
+				}
+			}
+		}
+	}
+	return x
+}
+
+// solveSubset returns the least squares solution using only the columns of a in subset,
+// the other coefficients being zero.
+func solveSubset(a, b *mat.Dense, subset []bool) []float64 {
+	n, p := a.Dims()
+	var cols []int
+	for j, ok := range subset {
+		if ok {
+			cols = append(cols, j)
+		}
+	}
+	x := make([]float64, p)
+	if len(cols) == 0 {
+		return x
+	}
+	reduced := mat.NewDense(n, len(cols), nil)
+	for k, j := range cols {
+		for i := 0; i < n; i++ {
+			reduced.Set(i, k, a.At(i, j))
+		}
+	}
+	for k, val := range solveLeastSquares(reduced, b) {
+		x[cols[k]] = val
+	}
+	return x
+}
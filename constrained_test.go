@@ -0,0 +1,139 @@
+package regression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNonNegative(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 30; i++ {
+		x, y, z := float64(i), math.Sin(float64(i)), math.Cos(float64(2*i))
+		data = append(data, DataPoint{Observed: -5 + 2*x - 0.5*y + z + 0.1*math.Sin(float64(5*i)), Variables: []float64{x, y, z}})
+	}
+
+	ols := &Regression{}
+	ols.Train(data...)
+	if err := ols.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if ols.Coeff(2) >= 0 {
+		t.Fatalf("Expected a negative least squares coefficient, got %v", ols.GetCoeffs())
+	}
+
+	r := &Regression{}
+	r.Train(data...)
+	r.SetNonNegative(true)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	coeffs := r.GetCoeffs()
+	if coeffs[2] != 0 {
+		t.Errorf("Expected the negative coefficient to be zeroed, got %v", coeffs)
+	}
+	for _, c := range coeffs[1:] {
+		if c < 0 {
+			t.Errorf("Expected non-negative coefficients, got %v", coeffs)
+		}
+	}
+	if coeffs[0] > -4 || math.Abs(coeffs[1]-2) > 0.1 || math.Abs(coeffs[3]-1) > 0.2 {
+		t.Errorf("Expected the other coefficients to stay close to [-5 2 _ 1], got %v", coeffs)
+	}
+}
+
+func TestNonNegativeOffset(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 30; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: -5 + 2*x + 3*y + 0.1*math.Cos(float64(5*i)), Variables: []float64{x, y}})
+	}
+	r.SetNonNegative(true)
+	r.SetNonNegativeOffset(true)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	coeffs := r.GetCoeffs()
+	if coeffs[0] != 0 {
+		t.Errorf("Expected the negative offset to be zeroed, got %v", coeffs)
+	}
+	for _, c := range coeffs {
+		if c < 0 {
+			t.Errorf("Expected non-negative coefficients, got %v", coeffs)
+		}
+	}
+	// the slope compensates for the missing offset
+	if coeffs[1] >= 2 || math.Abs(coeffs[2]-3) > 0.5 {
+		t.Errorf("Expected a lower slope and a coefficient close to 3, got %v", coeffs)
+	}
+
+	r.SetNonNegativeOffset(false)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Coeff(0) > -4 {
+		t.Errorf("Expected the offset to be unconstrained again, got %v", r.GetCoeffs())
+	}
+}
+
+func TestSetBound(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 30; i++ {
+		x, y := float64(i)/10, math.Sin(float64(i))
+		data = append(data, DataPoint{Observed: 1 + 2*x - 3*y + 0.01*math.Cos(float64(5*i)), Variables: []float64{x, y}})
+	}
+
+	r := &Regression{}
+	r.Train(data...)
+	r.SetBound(1, 0, 1.5)
+	r.SetBound(2, -2, math.Inf(1))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	coeffs := r.GetCoeffs()
+	if coeffs[1] != 1.5 || coeffs[2] != -2 {
+		t.Errorf("Expected the coefficients to be clamped to their bounds, got %v", coeffs)
+	}
+
+	// with the bounded coefficients known, the offset is their least squares fit
+	fixed := &Regression{}
+	fixed.Train(data...)
+	fixed.FixCoeff(1, 1.5)
+	fixed.FixCoeff(2, -2)
+	if err := fixed.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(coeffs[0]-fixed.Coeff(0)) > 1e-6 {
+		t.Errorf("Expected the offset %v, got %v", fixed.Coeff(0), coeffs[0])
+	}
+
+	// inactive bounds leave the least squares solution untouched
+	loose := &Regression{}
+	loose.Train(data...)
+	loose.SetBound(1, -10, 10)
+	if err := loose.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(loose.Coeff(1)-2) > 0.01 || math.Abs(loose.Coeff(2)+3) > 0.01 {
+		t.Errorf("Expected coefficients close to [1 2 -3], got %v", loose.GetCoeffs())
+	}
+
+	r.SetBound(1, 2, 1)
+	if err := r.Run(); err != ErrInvalidBound {
+		t.Errorf("Expected %v, got %v", ErrInvalidBound, err)
+	}
+	r.SetBound(1, 0, 1)
+	r.SetBound(5, 0, 1)
+	if err := r.Run(); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+
+	// accumulated fits only solve the unconstrained normal equations
+	streamed := &Regression{}
+	streamed.SetBound(1, 0, math.Inf(1))
+	if err := streamed.Accumulate(data...); err != nil {
+		t.Fatal(err)
+	}
+	if err := streamed.Run(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
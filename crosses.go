@@ -1,45 +1,148 @@
 package regression
 
 import (
+	"encoding/binary"
 	"math"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 type featureCross interface {
 	Calculate([]float64) []float64 // must return the same number of features each run
 }
 
+// namedCross is an optional interface for feature crosses able to label their outputs.
+type namedCross interface {
+	// Names returns one label per value returned by Calculate, varName giving the label of a base variable,
+	// or nil if the outputs can't be labeled.
+	Names(varName func(int) string) []string
+}
+
+// labelCross returns the labels of the outputs of cross, or nil if it can't label them.
+func labelCross(cross featureCross, varName func(int) string) []string {
+	if named, ok := cross.(namedCross); ok {
+		return named.Names(varName)
+	}
+	return nil
+}
+
+// dependentCross is an optional interface for feature crosses able to report their inputs.
+type dependentCross interface {
+	// Dependencies returns the indices of the variables used by Calculate.
+	Dependencies() []int
+}
+
 type functionalCross struct {
 	boundVars []int
 	crossFn   func([]float64) []float64
+	nameFn    func(varName func(int) string) []string
+	spec      *CrossSpec
 }
 
 func (c *functionalCross) Calculate(input []float64) []float64 {
 	return c.crossFn(input)
 }
 
+func (c *functionalCross) Names(varName func(int) string) []string {
+	if c.nameFn == nil {
+		return nil
+	}
+	return c.nameFn(varName)
+}
+
+func (c *functionalCross) Dependencies() []int {
+	return append([]int(nil), c.boundVars...)
+}
+
+// CrossSpec describes one of the built-in feature crosses, so that it can be serialized along with a
+// model: Kind is the name of its constructor without the Cross suffix, e.g. "Pow" for PowCross, Vars
+// the indices of its variables, Power the power of a PowCross and Crosses the crosses composed by
+// ComposeCrosses.
+type CrossSpec struct {
+	Kind    string      `json:"kind"`
+	Vars    []int       `json:"vars,omitempty"`
+	Power   float64     `json:"power,omitempty"`
+	Crosses []CrossSpec `json:"crosses,omitempty"`
+}
+
+// Cross returns the feature cross described by the spec.
+func (s CrossSpec) Cross() (featureCross, error) {
+	arity := map[string]int{"Pow": 1, "Divide": 2, "Abs": 1, "Identity": 1, "Sigmoid": 1}
+	if n, ok := arity[s.Kind]; ok && len(s.Vars) != n {
+		return nil, ErrVariableCount
+	}
+	switch s.Kind {
+	case "Pow":
+		return PowCross(s.Vars[0], s.Power), nil
+	case "Multiplier":
+		return MultiplierCross(s.Vars...), nil
+	case "Divide":
+		return DivideCross(s.Vars[0], s.Vars[1]), nil
+	case "Abs":
+		return AbsCross(s.Vars[0]), nil
+	case "Identity":
+		return IdentityCross(s.Vars[0]), nil
+	case "Sigmoid":
+		return SigmoidCross(s.Vars[0]), nil
+	case "Compose":
+		crosses := make([]featureCross, len(s.Crosses))
+		for i, spec := range s.Crosses {
+			cross, err := spec.Cross()
+			if err != nil {
+				return nil, err
+			}
+			crosses[i] = cross
+		}
+		return ComposeCrosses(crosses...), nil
+	default:
+		return nil, ErrNotSerializable
+	}
+}
+
+// checkVars checks that the spec, including the crosses it composes, only refers to variables below
+// numVars.
+func (s CrossSpec) checkVars(numVars int) error {
+	for _, v := range s.Vars {
+		if v < 0 || v >= numVars {
+			return ErrInvalidIndex
+		}
+	}
+	for _, spec := range s.Crosses {
+		if err := spec.checkVars(numVars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// specifyCross returns the spec of cross, or false if it is not a built-in feature cross.
+func specifyCross(cross featureCross) (CrossSpec, bool) {
+	if c, ok := cross.(*functionalCross); ok && c.spec != nil {
+		return *c.spec, true
+	}
+	return CrossSpec{}, false
+}
+
 // Feature cross based on computing the power of an input.
 func PowCross(i int, power float64) featureCross {
 	return &functionalCross{
 		boundVars: []int{i},
+		spec:      &CrossSpec{Kind: "Pow", Vars: []int{i}, Power: power},
 		crossFn: func(vars []float64) []float64 {
 			return []float64{math.Pow(vars[i], power)}
 		},
+		nameFn: func(varName func(int) string) []string {
+			return []string{varName(i) + "^" + strconv.FormatFloat(power, 'g', -1, 64)}
+		},
 	}
 }
 
 // Feature cross based on the multiplication of multiple inputs.
 func MultiplierCross(vars ...int) featureCross {
-	name := ""
-	for i, v := range vars {
-		name += strconv.Itoa(v)
-		if i < (len(vars) - 1) {
-			name += "*"
-		}
-	}
-
 	return &functionalCross{
 		boundVars: vars,
+		spec:      &CrossSpec{Kind: "Multiplier", Vars: vars},
 		crossFn: func(input []float64) []float64 {
 			var output float64 = 1
 			for _, variableIndex := range vars {
@@ -47,5 +150,169 @@ func MultiplierCross(vars ...int) featureCross {
 			}
 			return []float64{output}
 		},
+		nameFn: func(varName func(int) string) []string {
+			names := make([]string, len(vars))
+			for i, v := range vars {
+				names[i] = varName(v)
+			}
+			return []string{strings.Join(names, "*")}
+		},
+	}
+}
+
+// Feature cross based on the ratio of two inputs.
+// A zero denominator yields NaN rather than an infinity, so that the bad value is not silently fitted.
+func DivideCross(numerator, denominator int) featureCross {
+	return &functionalCross{
+		boundVars: []int{numerator, denominator},
+		spec:      &CrossSpec{Kind: "Divide", Vars: []int{numerator, denominator}},
+		crossFn: func(vars []float64) []float64 {
+			if vars[denominator] == 0 {
+				return []float64{math.NaN()}
+			}
+			return []float64{vars[numerator] / vars[denominator]}
+		},
+		nameFn: func(varName func(int) string) []string {
+			return []string{varName(numerator) + "/" + varName(denominator)}
+		},
+	}
+}
+
+// Feature cross based on the absolute value of an input, for V-shaped responses.
+func AbsCross(i int) featureCross {
+	return &functionalCross{
+		boundVars: []int{i},
+		spec:      &CrossSpec{Kind: "Abs", Vars: []int{i}},
+		crossFn: func(vars []float64) []float64 {
+			return []float64{math.Abs(vars[i])}
+		},
+		nameFn: func(varName func(int) string) []string {
+			return []string{"|" + varName(i) + "|"}
+		},
+	}
+}
+
+// Feature cross returning an input unchanged, e.g. to repeat a variable among crosses.
+func IdentityCross(i int) featureCross {
+	return &functionalCross{
+		boundVars: []int{i},
+		spec:      &CrossSpec{Kind: "Identity", Vars: []int{i}},
+		crossFn: func(vars []float64) []float64 {
+			return []float64{vars[i]}
+		},
+		nameFn: func(varName func(int) string) []string {
+			return []string{varName(i)}
+		},
+	}
+}
+
+// Feature cross based on the logistic function of an input, for bounded saturating effects.
+func SigmoidCross(i int) featureCross {
+	return &functionalCross{
+		boundVars: []int{i},
+		spec:      &CrossSpec{Kind: "Sigmoid", Vars: []int{i}},
+		crossFn: func(vars []float64) []float64 {
+			return []float64{1 / (1 + math.Exp(-vars[i]))}
+		},
+		nameFn: func(varName func(int) string) []string {
+			return []string{"sigmoid(" + varName(i) + ")"}
+		},
+	}
+}
+
+// crossCache memoizes the outputs of feature crosses, keyed by their input. It is safe for concurrent use.
+type crossCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string][]float64
+}
+
+// features appends to vars the outputs of the crosses, from the cache if possible.
+func (c *crossCache) features(vars []float64, crosses []featureCross) []float64 {
+	key := make([]byte, 8*len(vars))
+	for i, val := range vars {
+		binary.LittleEndian.PutUint64(key[8*i:], math.Float64bits(val))
+	}
+
+	c.mu.Lock()
+	outputs, ok := c.entries[string(key)]
+	c.mu.Unlock()
+	if ok {
+		return append(vars, outputs...)
+	}
+
+	n := len(vars)
+	for _, cross := range crosses {
+		vars = append(vars, cross.Calculate(vars[:n])...)
+	}
+
+	c.mu.Lock()
+	if len(c.entries) >= c.size {
+		c.entries = nil
+	}
+	if c.entries == nil {
+		c.entries = make(map[string][]float64, c.size)
+	}
+	c.entries[string(key)] = append([]float64(nil), vars[n:]...)
+	c.mu.Unlock()
+	return vars
+}
+
+func (c *crossCache) reset() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// Feature cross concatenating the outputs of several crosses, in order. This allows to register a
+// bundle of transformations with a single call to AddCross.
+func ComposeCrosses(cs ...featureCross) featureCross {
+	composed := &functionalCross{
+		crossFn: func(vars []float64) []float64 {
+			var output []float64
+			for _, c := range cs {
+				output = append(output, c.Calculate(vars)...)
+			}
+			return output
+		},
+		nameFn: func(varName func(int) string) []string {
+			var names []string
+			for _, c := range cs {
+				crossNames := labelCross(c, varName)
+				if crossNames == nil {
+					return nil
+				}
+				names = append(names, crossNames...)
+			}
+			return names
+		},
+	}
+
+	spec := &CrossSpec{Kind: "Compose"}
+	for _, c := range cs {
+		inner, ok := specifyCross(c)
+		if !ok {
+			spec = nil
+			break
+		}
+		spec.Crosses = append(spec.Crosses, inner)
+	}
+	composed.spec = spec
+
+	seen := map[int]bool{}
+	for _, c := range cs {
+		dependent, ok := c.(dependentCross)
+		if !ok {
+			// the dependencies are unknown
+			composed.boundVars = nil
+			break
+		}
+		for _, v := range dependent.Dependencies() {
+			if !seen[v] {
+				seen[v] = true
+				composed.boundVars = append(composed.boundVars, v)
+			}
+		}
 	}
+	return composed
 }
@@ -1,6 +1,8 @@
 package regression
 
 import (
+	"math"
+	"strconv"
 	"testing"
 )
 
@@ -27,3 +29,187 @@ func TestMultiplicationCrosses(t *testing.T) {
 		t.Errorf("Incorrect value, expected 6 got %.2f", cross1.Calculate([]float64{2, 3, 4, 5})[0])
 	}
 }
+
+func TestDivideCrosses(t *testing.T) {
+	cross := DivideCross(0, 1)
+	if v := cross.Calculate([]float64{6, 3})[0]; v != 2 {
+		t.Errorf("Incorrect value, expected 2 got %.2f", v)
+	}
+
+	cross = DivideCross(2, 0)
+	if v := cross.Calculate([]float64{4, 3, 1})[0]; v != 0.25 {
+		t.Errorf("Incorrect value, expected 0.25 got %.2f", v)
+	}
+
+	if v := cross.Calculate([]float64{0, 3, 1})[0]; !math.IsNaN(v) {
+		t.Errorf("Expected NaN on division by zero, got %.2f", v)
+	}
+
+	names := cross.(namedCross).Names(func(i int) string { return []string{"a", "b", "c"}[i] })
+	if len(names) != 1 || names[0] != "c/a" {
+		t.Errorf("Expected name c/a, got %v", names)
+	}
+}
+
+func TestAbsCrosses(t *testing.T) {
+	cross := AbsCross(1)
+	if v := cross.Calculate([]float64{-1, 2.5})[0]; v != 2.5 {
+		t.Errorf("Incorrect value, expected 2.5 got %.2f", v)
+	}
+	if v := cross.Calculate([]float64{1, -3})[0]; v != 3 {
+		t.Errorf("Incorrect value, expected 3 got %.2f", v)
+	}
+	if names := cross.(namedCross).Names(func(i int) string { return "x" + strconv.Itoa(i) }); names[0] != "|x1|" {
+		t.Errorf("Expected name |x1|, got %v", names)
+	}
+}
+
+func TestIdentityCrosses(t *testing.T) {
+	cross := IdentityCross(1)
+	if v := cross.Calculate([]float64{2, 3})[0]; v != 3 {
+		t.Errorf("Incorrect value, expected 3 got %.2f", v)
+	}
+}
+
+func TestCrossesOrdering(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + 2*x + 3*y + 4*x*x + 5*x*y + 6*math.Abs(y), Variables: []float64{x, y}})
+	}
+	r.AddCross(PowCross(0, 2))
+	r.AddCross(MultiplierCross(0, 1))
+	r.AddCross(AbsCross(1))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// offset, x, y, x^2, x*y, |y|
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(c-float64(i+1)) > 1e-6 {
+			t.Errorf("Expected coefficient %d to be %d, got %v", i, i+1, c)
+		}
+	}
+}
+
+func TestSigmoidCrosses(t *testing.T) {
+	cross := SigmoidCross(0)
+	if v := cross.Calculate([]float64{0})[0]; v != 0.5 {
+		t.Errorf("Incorrect value, expected 0.5 got %.2f", v)
+	}
+	if v := cross.Calculate([]float64{50})[0]; math.Abs(v-1) > 1e-9 {
+		t.Errorf("Incorrect value, expected 1 got %g", v)
+	}
+	if v := cross.Calculate([]float64{-50})[0]; v < 0 || v > 1e-9 {
+		t.Errorf("Incorrect value, expected 0 got %g", v)
+	}
+	if v := cross.Calculate([]float64{-1000})[0]; v != 0 {
+		t.Errorf("Incorrect value, expected 0 got %g", v)
+	}
+}
+
+// countingCross counts its calculations.
+type countingCross struct {
+	calls int
+}
+
+func (c *countingCross) Calculate(vars []float64) []float64 {
+	c.calls++
+	return []float64{vars[0] * vars[0]}
+}
+
+func TestCrossCache(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 10; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 1 + x + x*x + x*x*x, Variables: []float64{x}})
+	}
+	counter := &countingCross{}
+	r.AddCross(counter)
+	r.AddCross(PowCross(0, 3))
+	r.SetCrossCache(10)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// all the crosses are applied to the training data
+	for _, d := range r.Data {
+		x := d.Variables[0]
+		if len(d.Crosses) != 2 || d.Crosses[0] != x*x || d.Crosses[1] != x*x*x {
+			t.Errorf("Expected crosses [%v %v], got %v", x*x, x*x*x, d.Crosses)
+		}
+	}
+
+	counter.calls = 0
+	first, _ := r.Predict([]float64{2.5})
+	second, _ := r.Predict([]float64{2.5})
+	if first != second || counter.calls != 1 {
+		t.Errorf("Expected a single calculation for identical predictions, got %d", counter.calls)
+	}
+	if expected := 1 + 2.5 + 2.5*2.5 + 2.5*2.5*2.5; math.Abs(first-expected) > 1e-9 {
+		t.Errorf("Expected %v, got %v", expected, first)
+	}
+}
+
+func TestCrossDependencies(t *testing.T) {
+	r := &Regression{}
+	r.AddCross(MultiplierCross(0, 1, 3))
+	r.AddCross(DivideCross(2, 1))
+	r.AddCross(pairCross(0))
+
+	deps := r.CrossDependencies()
+	expected := [][]int{{0, 1, 3}, {2, 1}, nil}
+	if len(deps) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, deps)
+	}
+	for i := range expected {
+		if len(deps[i]) != len(expected[i]) {
+			t.Errorf("Expected %v, got %v", expected[i], deps[i])
+			continue
+		}
+		for j := range expected[i] {
+			if deps[i][j] != expected[i][j] {
+				t.Errorf("Expected %v, got %v", expected[i], deps[i])
+			}
+		}
+	}
+}
+
+func TestComposeCrosses(t *testing.T) {
+	cross := ComposeCrosses(PowCross(0, 2), MultiplierCross(0, 1))
+	output := cross.Calculate([]float64{3, 4})
+	if len(output) != 2 || output[0] != 9 || output[1] != 12 {
+		t.Errorf("Incorrect value, expected [9 12] got %v", output)
+	}
+	if deps := cross.(dependentCross).Dependencies(); len(deps) != 2 || deps[0] != 0 || deps[1] != 1 {
+		t.Errorf("Expected dependencies [0 1], got %v", deps)
+	}
+
+	r := &Regression{}
+	for i := 0; i < 15; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + x + y + 2*x*x - 3*x*y, Variables: []float64{x, y}})
+	}
+	r.AddCross(cross)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{1, 1, 1, 2, -3}
+	coeffs := r.GetCoeffs()
+	if len(coeffs) != len(expected) {
+		t.Fatalf("Expected coefficients %v, got %v", expected, coeffs)
+	}
+	for i, c := range expected {
+		if math.Abs(coeffs[i]-c) > 1e-6 {
+			t.Errorf("Expected coefficients %v, got %v", expected, coeffs)
+			break
+		}
+	}
+
+	r.SetVar(0, "a")
+	r.SetVar(1, "b")
+	names, _ := r.CoeffMap()
+	if _, ok := names["a*b"]; !ok || len(names) != 5 {
+		t.Errorf("Expected the composed outputs to be named, got %v", names)
+	}
+}
@@ -0,0 +1,132 @@
+package regression
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvConfig holds the settings of TrainCSV.
+type csvConfig struct {
+	comma   rune
+	columns []string
+	weight  string
+}
+
+// CSVOption configures TrainCSV.
+type CSVOption func(*csvConfig)
+
+// CSVComma sets the field delimiter, a comma by default.
+func CSVComma(comma rune) CSVOption {
+	return func(c *csvConfig) { c.comma = comma }
+}
+
+// CSVColumns selects the columns used as variables, in the given order. By default, all the columns
+// but the observed one and the weight one are variables, in the order of the header.
+func CSVColumns(names ...string) CSVOption {
+	return func(c *csvConfig) { c.columns = names }
+}
+
+// CSVWeight sets the column holding the weights of the data points, see DataPoint.Weight.
+func CSVWeight(name string) CSVOption {
+	return func(c *csvConfig) { c.weight = name }
+}
+
+// TrainCSV adds the rows of a CSV document as training data points. The first row is a header naming
+// the columns: the observedColumn one holds the observed values, and the names of the others are set
+// as the names of the variables, see SetObserved and SetVar. The cells are parsed as float64, surrounding
+// spaces being ignored. It fails with ErrUnknownColumn if a column is not in the header, and with the
+// parsing error of the first invalid cell, in which case no data point is added. The regression must
+// then be run.
+func (r *Regression) TrainCSV(rd io.Reader, observedColumn string, opts ...CSVOption) error {
+	config := csvConfig{comma: ','}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	reader := csv.NewReader(rd)
+	reader.Comma = config.comma
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	column := func(name string) (int, error) {
+		i, ok := index[name]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+		}
+		return i, nil
+	}
+	observed, err := column(observedColumn)
+	if err != nil {
+		return err
+	}
+	weight := -1
+	if config.weight != "" {
+		if weight, err = column(config.weight); err != nil {
+			return err
+		}
+	}
+	names := config.columns
+	if names == nil {
+		for i, name := range header {
+			if i != observed && i != weight {
+				names = append(names, strings.TrimSpace(name))
+			}
+		}
+	}
+	vars := make([]int, len(names))
+	for j, name := range names {
+		if vars[j], err = column(name); err != nil {
+			return err
+		}
+	}
+
+	var data []DataPoint
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		cell := func(i int) (float64, error) {
+			v, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d, column %s: %w", line, strings.TrimSpace(header[i]), err)
+			}
+			return v, nil
+		}
+		var d DataPoint
+		if d.Observed, err = cell(observed); err != nil {
+			return err
+		}
+		if weight >= 0 {
+			if d.Weight, err = cell(weight); err != nil {
+				return err
+			}
+		}
+		d.Variables = make([]float64, len(vars))
+		for j, i := range vars {
+			if d.Variables[j], err = cell(i); err != nil {
+				return err
+			}
+		}
+		data = append(data, d)
+	}
+
+	r.SetObserved(observedColumn)
+	for j, name := range names {
+		r.SetVar(j, name)
+	}
+	r.Train(data...)
+	return nil
+}
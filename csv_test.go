@@ -0,0 +1,56 @@
+package regression
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTrainCSV(t *testing.T) {
+	var doc strings.Builder
+	doc.WriteString("rooms, price, age\n")
+	for i := 0; i < 10; i++ {
+		rooms, age := float64(i%4+1), float64(i)
+		doc.WriteString(strconv.Itoa(int(rooms)) + "," + strconv.FormatFloat(50+30*rooms-2*age, 'g', -1, 64) + "," + strconv.Itoa(int(age)) + "\n")
+	}
+
+	r := &Regression{}
+	if err := r.TrainCSV(strings.NewReader(doc.String()), "price"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if r.GetObserved() != "price" || r.GetVar(0) != "rooms" || r.GetVar(1) != "age" {
+		t.Errorf("Expected price, rooms and age, got %s, %s and %s", r.GetObserved(), r.GetVar(0), r.GetVar(1))
+	}
+	for i, expected := range []float64{50, 30, -2} {
+		if math.Abs(r.Coeff(i)-expected) > 1e-9 {
+			t.Errorf("Expected coefficient %d to be %v, got %v", i, expected, r.Coeff(i))
+		}
+	}
+
+	// selecting and reordering the variables, with another delimiter and weights
+	semicolons := "w;y;a;b\n1;3;1;2\n2;5;2;3\n1;8;3;5\n"
+	r = &Regression{}
+	if err := r.TrainCSV(strings.NewReader(semicolons), "y", CSVComma(';'), CSVColumns("b", "a"), CSVWeight("w")); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Data) != 3 || r.Data[1].Weight != 2 || r.Data[1].Variables[0] != 3 || r.Data[1].Variables[1] != 2 || r.GetVar(0) != "b" {
+		t.Errorf("Unexpected data points %v", r.Data)
+	}
+
+	if err := (&Regression{}).TrainCSV(strings.NewReader(doc.String()), "rent"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected %v, got %v", ErrUnknownColumn, err)
+	}
+	r = &Regression{}
+	err := r.TrainCSV(strings.NewReader("x,y\n1,2\n2,n/a\n"), "y")
+	if !errors.Is(err, strconv.ErrSyntax) || !strings.Contains(err.Error(), "line 3, column y") {
+		t.Errorf("Expected a syntax error on line 3, got %v", err)
+	}
+	if len(r.Data) != 0 {
+		t.Errorf("Expected no data point to be added, got %d", len(r.Data))
+	}
+}
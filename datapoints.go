@@ -0,0 +1,107 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Add returns the data points with a new point appended, allowing for fluent construction:
+//
+//	d := regression.DataPoints{}.Add(11.2, 587000, 16.5, 6.2).Add(13.4, 643000, 20.5, 6.4)
+//
+// The variables are copied, so the caller can reuse its slice.
+func (d DataPoints) Add(observed float64, vars ...float64) DataPoints {
+	return append(d, DataPoint{Observed: observed, Variables: append([]float64(nil), vars...)})
+}
+
+// Validate checks that all the data points share the same number of variables, and that none has a
+// negative weight.
+func (d DataPoints) Validate() error {
+	for _, p := range d {
+		if len(p.Variables) != len(d[0].Variables) {
+			return ErrVariableCount
+		}
+		if p.Weight < 0 {
+			return ErrNegativeWeight
+		}
+	}
+	return nil
+}
+
+// DropVariable returns a copy of the data points without the variable at index.
+// The observed values are left untouched, and the outputs of the feature crosses are cleared, to be
+// recomputed from the remaining variables when a regression runs.
+func (d DataPoints) DropVariable(index int) (DataPoints, error) {
+	dropped := make(DataPoints, len(d))
+	for i, p := range d {
+		if index < 0 || index >= len(p.Variables) {
+			return nil, ErrInvalidIndex
+		}
+		vars := make([]float64, 0, len(p.Variables)-1)
+		vars = append(vars, p.Variables[:index]...)
+		p.Variables = append(vars, p.Variables[index+1:]...)
+		p.Crosses = nil
+		dropped[i] = p
+	}
+	return dropped, nil
+}
+
+// Shuffle randomly reorders the data points in place. The permutation only depends on the seed,
+// so that shuffles are reproducible.
+func (d DataPoints) Shuffle(seed int64) {
+	rnd := rand.New(rand.NewSource(seed))
+	rnd.Shuffle(len(d), func(i, j int) {
+		d[i], d[j] = d[j], d[i]
+	})
+}
+
+// Split shuffles a copy of the data points with the given seed, see Shuffle, and splits it into a
+// training set holding a share frac of them, rounded, and a test set holding the others.
+// The data points themselves are left untouched.
+func Split(data DataPoints, frac float64, seed int64) (train, test DataPoints, err error) {
+	if frac <= 0 || frac >= 1 {
+		return nil, nil, ErrInvalidProbability
+	}
+	shuffled := append(DataPoints(nil), data...)
+	shuffled.Shuffle(seed)
+	size := int(math.Round(frac * float64(len(shuffled))))
+	return shuffled[:size:size], shuffled[size:], nil
+}
+
+// TrimByResidual fits the data points with the configuration of model, that is its feature crosses and
+// fit options, and returns those whose residual lies between the lowerQ and upperQ quantiles of the
+// residuals, e.g. 0.05 and 0.95, dropping the most extreme ones. model itself is neither trained nor
+// run, and can then be trained on the trimmed data points for a fit which is less sensitive to outliers.
+func (d DataPoints) TrimByResidual(model *Regression, lowerQ, upperQ float64) (DataPoints, error) {
+	if lowerQ < 0 || upperQ > 1 || lowerQ >= upperQ {
+		return nil, ErrInvalidProbability
+	}
+	if len(d) == 0 {
+		return nil, ErrNotEnoughData
+	}
+
+	fit := model.withData(d)
+	if err := fit.Run(); err != nil {
+		return nil, err
+	}
+	residuals := make([]float64, len(d))
+	for i, p := range d {
+		predicted, err := fit.Predict(p.Variables)
+		if err != nil {
+			return nil, err
+		}
+		residuals[i] = p.Observed - predicted
+	}
+	sorted := append([]float64(nil), residuals...)
+	sort.Float64s(sorted)
+	lower, upper := quantile(sorted, lowerQ), quantile(sorted, upperQ)
+
+	trimmed := make(DataPoints, 0, len(d))
+	for i, p := range d {
+		if residuals[i] >= lower && residuals[i] <= upper {
+			trimmed = append(trimmed, p)
+		}
+	}
+	return trimmed, nil
+}
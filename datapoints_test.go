@@ -0,0 +1,180 @@
+package regression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDataPointsAdd(t *testing.T) {
+	d := DataPoints{}.
+		Add(3, 1).
+		Add(5, 2).
+		Add(7, 3).
+		Add(9, 4)
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Regression{}
+	r.Train(d...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.Coeff(0)-1) > 1e-9 || math.Abs(r.Coeff(1)-2) > 1e-9 {
+		t.Errorf("Expected coefficients [1 2], got %v", r.GetCoeffs())
+	}
+
+	if err := d.Add(11, 5, 6).Validate(); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+}
+
+func TestDropVariable(t *testing.T) {
+	d := DataPoints{}.Add(1, 10, 20, 30).Add(2, 11, 21, 31)
+	d[0].Crosses = []float64{400}
+
+	dropped, err := d.DropVariable(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]float64{{10, 30}, {11, 31}}
+	for i, p := range dropped {
+		if p.Observed != float64(i+1) {
+			t.Errorf("Expected observed %d, got %v", i+1, p.Observed)
+		}
+		for j, v := range expected[i] {
+			if p.Variables[j] != v || len(p.Variables) != 2 {
+				t.Errorf("Expected variables %v, got %v", expected[i], p.Variables)
+			}
+		}
+	}
+	if dropped[0].Crosses != nil {
+		t.Errorf("Expected the stale cross outputs to be cleared, got %v", dropped[0].Crosses)
+	}
+	if d[0].Variables[1] != 20 {
+		t.Error("Expected the original data points to be left untouched")
+	}
+
+	if _, err := d.DropVariable(3); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	d := DataPoints{}
+	for i := 0; i < 20; i++ {
+		d = d.Add(float64(i), float64(i))
+	}
+	d1 := append(DataPoints(nil), d...)
+	d2 := append(DataPoints(nil), d...)
+	d1.Shuffle(42)
+	d2.Shuffle(42)
+
+	moved := false
+	for i := range d {
+		if d1[i].Observed != d2[i].Observed {
+			t.Fatalf("Expected the same permutation for the same seed, got %v and %v at %d", d1[i].Observed, d2[i].Observed, i)
+		}
+		moved = moved || d1[i].Observed != d[i].Observed
+	}
+	if !moved {
+		t.Error("Expected the data points to be shuffled")
+	}
+}
+
+func TestTrimByResidual(t *testing.T) {
+	d := DataPoints{}
+	for i := 0; i < 40; i++ {
+		x := float64(i)
+		observed := 2*x + 1 + 0.5*math.Sin(3*x)
+		if i%10 == 3 {
+			observed += 40
+		}
+		d = d.Add(observed, x)
+	}
+
+	r := &Regression{}
+	trimmed, err := d.TrimByResidual(r, 0, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trimmed) != 36 {
+		t.Errorf("Expected 36 data points to remain, got %d", len(trimmed))
+	}
+	if r.Ready || len(r.Data) != 0 {
+		t.Error("Expected the model to be left untouched")
+	}
+
+	// the sums accumulated by the model are neither used nor changed
+	streamed := &Regression{}
+	if err := streamed.Accumulate(DataPoint{Observed: 1, Variables: []float64{0}}, DataPoint{Observed: 5, Variables: []float64{1}}, DataPoint{Observed: 0, Variables: []float64{2}}); err != nil {
+		t.Fatal(err)
+	}
+	trimmedStreamed, err := d.TrimByResidual(streamed, 0, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trimmedStreamed) != len(trimmed) {
+		t.Errorf("Expected %d data points to remain, got %d", len(trimmed), len(trimmedStreamed))
+	}
+	if streamed.stream.observations != 3 {
+		t.Errorf("Expected 3 accumulated data points, got %d", streamed.stream.observations)
+	}
+	r.Train(d...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	clean := &Regression{}
+	clean.Train(trimmed...)
+	if err := clean.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if clean.R2 <= r.R2+0.1 {
+		t.Errorf("Expected trimming to improve R^2, got %.4f vs %.4f", clean.R2, r.R2)
+	}
+
+	if _, err := d.TrimByResidual(r, 0.6, 0.4); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+	if _, err := d[:1].TrimByResidual(r, 0, 0.9); err != ErrNotEnoughData {
+		t.Errorf("Expected %v, got %v", ErrNotEnoughData, err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	var d DataPoints
+	for i := 0; i < 10; i++ {
+		d = d.Add(float64(i), float64(i))
+	}
+	train, test, err := Split(d, 0.75, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(train) != 8 || len(test) != 2 {
+		t.Fatalf("Expected 8 training and 2 test data points, got %d and %d", len(train), len(test))
+	}
+	seen := map[float64]bool{}
+	for _, p := range append(append(DataPoints(nil), train...), test...) {
+		seen[p.Observed] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("Expected every data point in one of the sets, got %v and %v", train, test)
+	}
+	for i, p := range d {
+		if p.Observed != float64(i) {
+			t.Fatalf("Expected the data points to be left untouched, got %v", d)
+		}
+	}
+
+	again, _, _ := Split(d, 0.75, 1)
+	for i := range train {
+		if again[i].Observed != train[i].Observed {
+			t.Errorf("Expected the same seed to give the same split, got %v and %v", train, again)
+			break
+		}
+	}
+
+	if _, _, err := Split(d, 1, 1); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+}
@@ -0,0 +1,544 @@
+package regression
+
+import (
+	"encoding/json"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ConditionNumber returns the condition number of the design matrix, the ratio of its largest to its
+// smallest singular value. Large values (say above 1e10) indicate collinear or badly scaled variables,
+// such as high powers in feature crosses, and coefficients which are numerically unreliable:
+// standardizing the variables may then help.
+func (r *Regression) ConditionNumber() (float64, error) {
+	if err := r.requireData(); err != nil {
+		return 0, err
+	}
+	var svd mat.SVD
+	if !svd.Factorize(r.variables, mat.SVDNone) {
+		return 0, ErrSingularMatrix
+	}
+	return svd.Cond(), nil
+}
+
+// VIF returns the variance inflation factor of each feature, the variables followed by the feature
+// crosses: 1/(1-R²ⱼ), R²ⱼ being the R2 of the regression of feature j on the other ones. It is the
+// factor by which the variance of the coefficient of the feature is inflated by its correlation with
+// the others, values above 10 being the usual sign of a problematic multicollinearity. Features which
+// are linear combinations of the others have an infinite factor.
+func (r *Regression) VIF() ([]float64, error) {
+	if err := r.requireData(); err != nil {
+		return nil, err
+	}
+
+	n, p := r.variables.Dims()
+	if p == 2 {
+		return []float64{1}, nil
+	}
+	others := mat.NewDense(n, p-1, nil)
+	feature := mat.NewDense(n, 1, nil)
+	vif := make([]float64, p-1)
+	for j := 1; j < p; j++ {
+		for i := 0; i < n; i++ {
+			for k, col := 0, 0; k < p; k++ {
+				if k == j {
+					continue
+				}
+				others.Set(i, col, r.variables.At(i, k))
+				col++
+			}
+			feature.Set(i, 0, r.variables.At(i, j))
+		}
+
+		var c, fitted mat.Dense
+		// an ill-conditioned solve still fits the feature as well as the other ones allow
+		if err := c.Solve(others, feature); err != nil {
+			if _, ok := err.(mat.Condition); !ok {
+				return nil, ErrSingularMatrix
+			}
+		}
+		fitted.Mul(others, &c)
+		var mean, sse, sst float64
+		for i := 0; i < n; i++ {
+			mean += feature.At(i, 0) / float64(n)
+		}
+		for i := 0; i < n; i++ {
+			sse += math.Pow(feature.At(i, 0)-fitted.At(i, 0), 2)
+			sst += math.Pow(feature.At(i, 0)-mean, 2)
+		}
+		if sst == 0 || sse <= sst*1e-12 {
+			vif[j-1] = math.Inf(1)
+			continue
+		}
+		vif[j-1] = sst / sse
+	}
+	return vif, nil
+}
+
+// ResidualsNormalityTest runs a Jarque-Bera test on the residuals, which under the null hypothesis of
+// normally distributed residuals follows a chi-squared distribution with 2 degrees of freedom.
+// A small p-value means that the residuals are unlikely to be normal.
+func (r *Regression) ResidualsNormalityTest() (statistic, pValue float64, err error) {
+	if err := r.requireResiduals(); err != nil {
+		return 0, 0, err
+	}
+
+	residuals := r.residuals()
+	n := float64(len(residuals))
+	var mean float64
+	for _, e := range residuals {
+		mean += e
+	}
+	mean /= n
+
+	var m2, m3, m4 float64
+	for _, e := range residuals {
+		d := e - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	m2, m3, m4 = m2/n, m3/n, m4/n
+	if m2 == 0 {
+		return 0, 0, ErrZeroVariance
+	}
+	skewness := m3 / math.Pow(m2, 1.5)
+	kurtosis := m4 / (m2 * m2)
+
+	statistic = n / 6 * (skewness*skewness + (kurtosis-3)*(kurtosis-3)/4)
+	return statistic, distuv.ChiSquared{K: 2}.Survival(statistic), nil
+}
+
+// ResidualACF returns the sample autocorrelation of the residuals, in training order, for the lags
+// 1 to maxLag. Values far from zero, compared to 2/sqrt(n), mean that the residuals are serially
+// correlated, which is common when the data points form a time series.
+func (r *Regression) ResidualACF(maxLag int) ([]float64, error) {
+	if err := r.requireResiduals(); err != nil {
+		return nil, err
+	}
+	residuals := r.residuals()
+	n := len(residuals)
+	if maxLag < 1 || maxLag >= n {
+		return nil, ErrInvalidIndex
+	}
+
+	var mean float64
+	for _, e := range residuals {
+		mean += e
+	}
+	mean /= float64(n)
+
+	var c0 float64
+	for _, e := range residuals {
+		c0 += (e - mean) * (e - mean)
+	}
+	if c0 == 0 {
+		return nil, ErrZeroVariance
+	}
+	acf := make([]float64, maxLag)
+	for k := range acf {
+		lag := k + 1
+		var ck float64
+		for i := lag; i < n; i++ {
+			ck += (residuals[i] - mean) * (residuals[i-lag] - mean)
+		}
+		acf[k] = ck / c0
+	}
+	return acf, nil
+}
+
+// DurbinWatson returns the Durbin-Watson statistic of the residuals, in training order,
+// Σ(eᵢ-eᵢ₋₁)²/Σeᵢ². It ranges from 0 to 4 and is close to 2 when the residuals are not serially
+// correlated, values well below 2 indicating a positive autocorrelation and well above 2 a negative
+// one, either of which invalidates the standard errors of the coefficients.
+func (r *Regression) DurbinWatson() (float64, error) {
+	if err := r.requireResiduals(); err != nil {
+		return 0, err
+	}
+
+	residuals := r.residuals()
+	var num, den float64
+	for i, e := range residuals {
+		den += e * e
+		if i > 0 {
+			num += (e - residuals[i-1]) * (e - residuals[i-1])
+		}
+	}
+	if den == 0 {
+		return 0, ErrZeroVariance
+	}
+	return num / den, nil
+}
+
+// BreuschPagan runs the studentized Breusch-Pagan test of heteroskedasticity, as proposed by Koenker:
+// the squared residuals are regressed on the features, and n times the R2 of that auxiliary regression
+// follows a chi-squared distribution with p degrees of freedom, p being the number of features, under
+// the null hypothesis of a constant variance. A small p-value means that the variance of the residuals
+// depends on the features, in which case weighted least squares or RobustStdErrors are advisable.
+func (r *Regression) BreuschPagan() (statistic, pValue float64, err error) {
+	if err := r.requireResiduals(); err != nil {
+		return 0, 0, err
+	}
+
+	variables, _ := r.whitened()
+	n, p := variables.Dims()
+	squares := mat.NewDense(n, 1, nil)
+	var mean float64
+	for i, e := range r.residuals() {
+		squares.Set(i, 0, e*e)
+		mean += e * e / float64(n)
+	}
+	var sst float64
+	for i := 0; i < n; i++ {
+		sst += math.Pow(squares.At(i, 0)-mean, 2)
+	}
+	if sst == 0 {
+		return 0, 0, ErrZeroVariance
+	}
+
+	var c, fitted mat.Dense
+	if err := c.Solve(variables, squares); err != nil {
+		return 0, 0, ErrSingularMatrix
+	}
+	fitted.Mul(variables, &c)
+	var sse float64
+	for i := 0; i < n; i++ {
+		sse += math.Pow(squares.At(i, 0)-fitted.At(i, 0), 2)
+	}
+
+	statistic = float64(n) * (1 - sse/sst)
+	return statistic, distuv.ChiSquared{K: float64(p - 1)}.Survival(statistic), nil
+}
+
+// Influence holds the influence diagnostics of a data point on a fit.
+type Influence struct {
+	Leverage      float64 // diagonal element hᵢᵢ of the hat matrix, between 0 and 1
+	CooksDistance float64 // change of the fit when the data point is left out, eᵢ²hᵢᵢ/(p s² (1-hᵢᵢ)²)
+	// Studentized is the internally studentized residual eᵢ/(s sqrt(1-hᵢᵢ)), which has a unit variance.
+	// The residual eᵢ is the observed minus the predicted value, the opposite of the Error of the data point.
+	Studentized float64
+	// ExternallyStudentized is the residual studentized with the residual standard error of the fit
+	// without the data point, which follows a t distribution with n-p-1 degrees of freedom, so that
+	// values above 3 in absolute value are outliers.
+	ExternallyStudentized float64
+}
+
+// Influence returns the influence diagnostics of each data point of the last run, in training order.
+// Data points with a high leverage have unusual variables, and those with a Cook's distance above 1,
+// or well above the others, single-handedly drive the fit.
+func (r *Regression) Influence() ([]Influence, error) {
+	if err := r.checkInference(); err != nil {
+		return nil, err
+	}
+	leverage, err := r.leverage()
+	if err != nil {
+		return nil, err
+	}
+
+	n, _ := r.variables.Dims()
+	s2, df := r.residualVariance(), float64(r.residualDF())
+	scale := float64(n-r.residualDF()) * s2
+	influence := make([]Influence, n)
+	for i, e := range r.residuals() {
+		h := leverage[i]
+		studentized := e / math.Sqrt(s2*(1-h))
+		influence[i] = Influence{
+			Leverage:      h,
+			CooksDistance: e * e * h / (scale * (1 - h) * (1 - h)),
+			Studentized:   studentized,
+			// the variance without the data point is ((n-p)s² - eᵢ²/(1-hᵢᵢ))/(n-p-1)
+			ExternallyStudentized: studentized * math.Sqrt((df-1)/(df-studentized*studentized)),
+		}
+	}
+	return influence, nil
+}
+
+// Diagnostics bundles the goodness-of-fit statistics of a regression.
+// They are computed on the scale of the fit, that is on the transformed observed values when
+// TransformResponse is used, and weighted when data points have weights.
+type Diagnostics struct {
+	Observations     int     `json:"observations"`        // number of data points
+	Variables        int     `json:"variables"`           // number of variables and feature crosses, excluding the offset
+	R2               float64 `json:"r2"`                  // coefficient of determination
+	AdjustedR2       float64 `json:"adjusted_r2"`         // R2 adjusted for the number of variables
+	FStatistic       float64 `json:"f_statistic"`         // F statistic of the overall significance of the regression
+	AIC              float64 `json:"aic"`                 // Akaike information criterion, n ln(SSE/n) + 2k, k fitted coefficients
+	BIC              float64 `json:"bic"`                 // Bayesian information criterion, n ln(SSE/n) + k ln(n)
+	ResidualStdError float64 `json:"residual_std_error"`  // estimate of the standard deviation of the residuals
+	JarqueBera       float64 `json:"jarque_bera"`         // statistic of the normality test of the residuals
+	JarqueBeraPValue float64 `json:"jarque_bera_p_value"` // p-value of the normality test of the residuals
+	// NonNormalResiduals reports whether the Jarque-Bera test rejects the normality of the residuals at
+	// the 5% level, in which case the p-values and intervals, which assume normal residuals, are suspect.
+	NonNormalResiduals bool `json:"non_normal_residuals"`
+}
+
+// normalityLevel is the significance level at which Diagnostics flags non-normal residuals.
+const normalityLevel = 0.05
+
+// Diagnostics returns the goodness-of-fit statistics of the regression in one pass. It requires a least
+// squares fit, like the inference statistics. Fixed coefficients are not counted in the degrees of
+// freedom, and the F statistic is NaN when no coefficient but the offset is fitted.
+func (r *Regression) Diagnostics() (Diagnostics, error) {
+	if err := r.checkInference(); err != nil {
+		return Diagnostics{}, err
+	}
+
+	sse, sst := r.sumsOfSquares()
+	n, p := r.variables.Dims()
+	df := r.residualDF()
+	k := n - df
+	nf, dff := float64(n), float64(df)
+	f := math.NaN()
+	if test, err := r.FTest(); err == nil {
+		f = test.F
+	}
+	// residuals which are all 0 are not evidence against normality
+	jb, jbPValue, err := r.ResidualsNormalityTest()
+	if err != nil {
+		jb, jbPValue = 0, 1
+	}
+	return Diagnostics{
+		Observations:       n,
+		Variables:          p - 1,
+		R2:                 1 - sse/sst,
+		AdjustedR2:         1 - (sse/dff)/(sst/(nf-1)),
+		FStatistic:         f,
+		AIC:                AIC.score(sse, sst, n, k),
+		BIC:                BIC.score(sse, sst, n, k),
+		ResidualStdError:   math.Sqrt(sse / dff),
+		JarqueBera:         jb,
+		JarqueBeraPValue:   jbPValue,
+		NonNormalResiduals: jbPValue < normalityLevel,
+	}, nil
+}
+
+// InformationCriteria holds the information criteria of a fit, which balance its goodness of fit
+// against its number of coefficients k. They only compare fits of the same observations, lower being
+// better, whatever their feature crosses.
+type InformationCriteria struct {
+	AIC  float64 // Akaike information criterion, n ln(SSE/n) + 2k
+	AICc float64 // AIC corrected for small samples, AIC + 2k(k+1)/(n-k-1)
+	BIC  float64 // Bayesian information criterion, n ln(SSE/n) + k ln(n)
+}
+
+// Criteria returns the information criteria of the last run. Fixed coefficients are not counted in k.
+// AICc is NaN when there are fewer than k+2 observations.
+func (r *Regression) Criteria() (InformationCriteria, error) {
+	if err := r.requireResiduals(); err != nil {
+		return InformationCriteria{}, err
+	}
+
+	sse, sst := r.sumsOfSquares()
+	n, _ := r.variables.Dims()
+	k := n - r.residualDF()
+	c := InformationCriteria{
+		AIC:  AIC.score(sse, sst, n, k),
+		AICc: math.NaN(),
+		BIC:  BIC.score(sse, sst, n, k),
+	}
+	if n > k+1 {
+		c.AICc = c.AIC + float64(2*k*(k+1))/float64(n-k-1)
+	}
+	return c, nil
+}
+
+// sumsOfSquares returns the residual and total sums of squares of the last run, on the scale of the fit.
+// The total sum of squares is taken around the fit of the offset alone, that is around the mean of the
+// observed values, or around the whitened offset column for generalized least squares.
+func (r *Regression) sumsOfSquares() (sse, sst float64) {
+	for _, e := range r.residuals() {
+		sse += e * e
+	}
+
+	n, _ := r.observed.Dims()
+	weight := func(i int) float64 {
+		if r.weights == nil {
+			return 1
+		}
+		return r.weights[i]
+	}
+	// the offset column is all ones but once whitened
+	var total, wtotal float64
+	for i := 0; i < n; i++ {
+		x := r.variables.At(i, 0)
+		total += weight(i) * x * r.observed.At(i, 0)
+		wtotal += weight(i) * x * x
+	}
+	mean := total / wtotal
+	for i := 0; i < n; i++ {
+		sst += weight(i) * math.Pow(r.observed.At(i, 0)-mean*r.variables.At(i, 0), 2)
+	}
+	return sse, sst
+}
+
+// PRESS returns the predicted residual error sum of squares, Σ(eᵢ/(1-hᵢᵢ))², which is the sum of the
+// squared errors of leave-one-out predictions, computed without refitting. Like LOOCV, it only applies
+// to least squares fits without fixed coefficients.
+func (r *Regression) PRESS() (float64, error) {
+	_, press, err := r.press()
+	return press, err
+}
+
+// press returns the leave-one-out residuals and their sum of squares, for PRESS and LOOCV.
+func (r *Regression) press() ([]float64, float64, error) {
+	if err := r.requireData(); err != nil {
+		return nil, 0, err
+	}
+	if len(r.fixed) > 0 || !r.leastSquares() {
+		return nil, 0, ErrUnsupportedFit
+	}
+	residuals, err := r.looResiduals()
+	if err != nil {
+		return nil, 0, err
+	}
+	var press float64
+	for _, e := range residuals {
+		press += e * e
+	}
+	return residuals, press, nil
+}
+
+// PredictedR2 returns 1 - PRESS/SStot, which measures how well the model predicts observations it was
+// not trained on. It is lower than R2, much more so when the model is overfitted. It fails like PRESS.
+func (r *Regression) PredictedR2() (float64, error) {
+	press, err := r.PRESS()
+	if err != nil {
+		return 0, err
+	}
+	_, sst := r.sumsOfSquares()
+	return 1 - press/sst, nil
+}
+
+// CoeffSummary holds the estimate of a coefficient along with its inference statistics.
+type CoeffSummary struct {
+	Name     string  `json:"name"`
+	Estimate float64 `json:"estimate"`
+	StdError float64 `json:"std_error"`
+	TStat    float64 `json:"t_stat"`
+	PValue   float64 `json:"p_value"`
+}
+
+// DiagnosticsJSON returns the Diagnostics and, for each coefficient, its name, estimate, standard
+// error, t statistic and p-value, marshaled as a single JSON object:
+//
+//	{"observations": 20, ..., "coefficients": [{"name": "(Intercept)", "estimate": 3.2, ...}, ...]}
+//
+// The statistics which are not finite, such as the t statistics and p-values of fixed coefficients or
+// the F statistic of a fit of the offset alone, are null.
+func (r *Regression) DiagnosticsJSON() ([]byte, error) {
+	d, err := r.Diagnostics()
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := r.coeffReports()
+	if err != nil {
+		return nil, err
+	}
+	report := struct {
+		diagnosticsJSON
+		Coefficients []coeffSummaryJSON `json:"coefficients"`
+	}{
+		diagnosticsJSON: diagnosticsJSON{
+			Observations:       d.Observations,
+			Variables:          d.Variables,
+			R2:                 jsonFloat(d.R2),
+			AdjustedR2:         jsonFloat(d.AdjustedR2),
+			FStatistic:         jsonFloat(d.FStatistic),
+			AIC:                jsonFloat(d.AIC),
+			BIC:                jsonFloat(d.BIC),
+			ResidualStdError:   jsonFloat(d.ResidualStdError),
+			JarqueBera:         jsonFloat(d.JarqueBera),
+			JarqueBeraPValue:   jsonFloat(d.JarqueBeraPValue),
+			NonNormalResiduals: d.NonNormalResiduals,
+		},
+		Coefficients: make([]coeffSummaryJSON, len(coeffs)),
+	}
+	for i, c := range coeffs {
+		report.Coefficients[i] = coeffSummaryJSON{
+			Name:     c.Name,
+			Estimate: jsonFloat(c.Estimate),
+			StdError: jsonFloat(c.StdError),
+			TStat:    jsonFloat(c.TStat),
+			PValue:   jsonFloat(c.PValue),
+		}
+	}
+	return json.Marshal(report)
+}
+
+// diagnosticsJSON is the JSON form of Diagnostics, with the same keys.
+type diagnosticsJSON struct {
+	Observations       int       `json:"observations"`
+	Variables          int       `json:"variables"`
+	R2                 jsonFloat `json:"r2"`
+	AdjustedR2         jsonFloat `json:"adjusted_r2"`
+	FStatistic         jsonFloat `json:"f_statistic"`
+	AIC                jsonFloat `json:"aic"`
+	BIC                jsonFloat `json:"bic"`
+	ResidualStdError   jsonFloat `json:"residual_std_error"`
+	JarqueBera         jsonFloat `json:"jarque_bera"`
+	JarqueBeraPValue   jsonFloat `json:"jarque_bera_p_value"`
+	NonNormalResiduals bool      `json:"non_normal_residuals"`
+}
+
+// coeffSummaryJSON is the JSON form of CoeffSummary, with the same keys.
+type coeffSummaryJSON struct {
+	Name     string    `json:"name"`
+	Estimate jsonFloat `json:"estimate"`
+	StdError jsonFloat `json:"std_error"`
+	TStat    jsonFloat `json:"t_stat"`
+	PValue   jsonFloat `json:"p_value"`
+}
+
+// jsonFloat is a float64 which is marshaled as null when it is NaN or infinite, which JSON can't
+// represent, and unmarshaled from null as NaN.
+type jsonFloat float64
+
+// MarshalJSON implements json.Marshaler.
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(f))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = jsonFloat(math.NaN())
+		return nil
+	}
+	return json.Unmarshal(data, (*float64)(f))
+}
+
+// coeffReports returns the inference statistics of each coefficient, using the standard errors of the
+// estimator selected with SetCovarianceEstimator. Fixed coefficients have NaN t statistics and p-values,
+// like with TStat and PValue.
+func (r *Regression) coeffReports() ([]CoeffSummary, error) {
+	stdErrors, err := r.stdErrors()
+	if err != nil {
+		return nil, err
+	}
+	_, p := r.variables.Dims()
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(r.residualDF())}
+
+	names := append([]string{"(Intercept)"}, r.featureNames()...)
+	reports := make([]CoeffSummary, p)
+	for i := range reports {
+		t, pValue := r.Coeff(i)/stdErrors[i], math.NaN()
+		if _, ok := r.fixed[i]; ok {
+			t = math.NaN()
+		} else {
+			pValue = 2 * dist.Survival(math.Abs(t))
+		}
+		reports[i] = CoeffSummary{
+			Name:     names[i],
+			Estimate: r.Coeff(i),
+			StdError: stdErrors[i],
+			TStat:    t,
+			PValue:   pValue,
+		}
+	}
+	return reports, nil
+}
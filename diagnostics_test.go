@@ -0,0 +1,605 @@
+package regression
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestConditionNumber(t *testing.T) {
+	data := []DataPoint{
+		{Observed: 6, Variables: []float64{2}},
+		{Observed: 20, Variables: []float64{4}},
+		{Observed: 30, Variables: []float64{5}},
+		{Observed: 72, Variables: []float64{8}},
+		{Observed: 156, Variables: []float64{12}},
+	}
+
+	r := &Regression{}
+	if _, err := r.ConditionNumber(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(data...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	linear, err := r.ConditionNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crossed := &Regression{}
+	crossed.Train(data...)
+	crossed.AddCross(PowCross(0, 2))
+	crossed.AddCross(PowCross(0, 7))
+	if err := crossed.Run(); err != nil {
+		t.Fatal(err)
+	}
+	high, err := crossed.ConditionNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if linear > 100 || high < 1e6 {
+		t.Errorf("Expected a small condition number without crosses and a large one with them, got %g and %g", linear, high)
+	}
+}
+
+func TestResidualsNormalityTest(t *testing.T) {
+	r := &Regression{}
+	if _, _, err := r.ResidualsNormalityTest(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 100; i++ {
+		x := float64(i)
+		observed := 3*x + 1
+		// heavily skewed errors
+		if i%10 == 0 {
+			observed += 50
+		}
+		r.Train(DataPoint{Observed: observed, Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	statistic, pValue, err := r.ResidualsNormalityTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pValue > 0.01 {
+		t.Errorf("Expected a small p-value, got %g (statistic %g)", pValue, statistic)
+	}
+
+	d, err := r.Diagnostics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.JarqueBera != statistic || d.JarqueBeraPValue != pValue || !d.NonNormalResiduals {
+		t.Errorf("Expected flagged residuals with statistic %g, got %+v", statistic, d)
+	}
+
+	normal := &Regression{}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x := float64(i)
+		normal.Train(DataPoint{Observed: 3*x + 1 + rnd.NormFloat64(), Variables: []float64{x}})
+	}
+	if err := normal.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := normal.Diagnostics(); d.NonNormalResiduals {
+		t.Errorf("Expected normal residuals not to be flagged, got a p-value of %g", d.JarqueBeraPValue)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.Diagnostics(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(MakeDataPoints([][]float64{
+		{1, 1, 4},
+		{3, 2, 3},
+		{4, 3, 5},
+		{8, 4, 1},
+		{9, 5, 2},
+		{12, 6, 6},
+	}, 0)...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := r.Diagnostics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Observations != 6 || d.Variables != 2 {
+		t.Errorf("Expected 6 observations and 2 variables, got %d and %d", d.Observations, d.Variables)
+	}
+	if math.Abs(d.R2-r.R2) > 1e-9 {
+		t.Errorf("Expected R^2 %v, got %v", r.R2, d.R2)
+	}
+
+	var sse float64
+	for _, p := range r.Data {
+		sse += p.Error * p.Error
+	}
+	if expected := 1 - (1-r.R2)*5/3; math.Abs(d.AdjustedR2-expected) > 1e-9 {
+		t.Errorf("Expected adjusted R^2 %v, got %v", expected, d.AdjustedR2)
+	}
+	if expected := (r.R2 / 2) / ((1 - r.R2) / 3); math.Abs(d.FStatistic-expected) > 1e-6 {
+		t.Errorf("Expected F statistic %v, got %v", expected, d.FStatistic)
+	}
+	if expected := math.Sqrt(sse / 3); math.Abs(d.ResidualStdError-expected) > 1e-9 {
+		t.Errorf("Expected residual standard error %v, got %v", expected, d.ResidualStdError)
+	}
+	if expected := 6*math.Log(sse/6) + 6; math.Abs(d.AIC-expected) > 1e-9 {
+		t.Errorf("Expected AIC %v, got %v", expected, d.AIC)
+	}
+	if expected := 6*math.Log(sse/6) + 3*math.Log(6); math.Abs(d.BIC-expected) > 1e-9 {
+		t.Errorf("Expected BIC %v, got %v", expected, d.BIC)
+	}
+
+	// a fixed slope leaves 4 residual degrees of freedom
+	r.FixCoeff(2, 0.5)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = r.Diagnostics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sse = 0
+	for _, p := range r.Data {
+		sse += p.Error * p.Error
+	}
+	if expected := math.Sqrt(sse / 4); math.Abs(d.ResidualStdError-expected) > 1e-9 {
+		t.Errorf("Expected residual standard error %v, got %v", expected, d.ResidualStdError)
+	}
+	if expected := 6*math.Log(sse/6) + 4; math.Abs(d.AIC-expected) > 1e-9 {
+		t.Errorf("Expected AIC %v, got %v", expected, d.AIC)
+	}
+
+	lasso := &Regression{}
+	lasso.Train(r.Data...)
+	lasso.SetRegularization(L1, 0.1)
+	if err := lasso.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lasso.Diagnostics(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestPredictedR2(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.PRESS(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 12; i++ {
+		x := float64(i) / 4
+		r.Train(DataPoint{Observed: 2*x + math.Sin(5*x), Variables: []float64{x}})
+	}
+	for p := 2.0; p <= 5; p++ {
+		r.AddCross(PowCross(0, p))
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	press, err := r.PRESS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// check against actual leave-one-out refits
+	var expected float64
+	for i := range r.Data {
+		loo := &Regression{}
+		for p := 2.0; p <= 5; p++ {
+			loo.AddCross(PowCross(0, p))
+		}
+		for j, d := range r.Data {
+			if j != i {
+				loo.Train(DataPoint{Observed: d.Observed, Variables: d.Variables})
+			}
+		}
+		if err := loo.Run(); err != nil {
+			t.Fatal(err)
+		}
+		predicted, _ := loo.Predict(r.Data[i].Variables)
+		expected += math.Pow(predicted-r.Data[i].Observed, 2)
+	}
+	if math.Abs(press-expected)/expected > 1e-6 {
+		t.Errorf("Expected PRESS %v, got %v", expected, press)
+	}
+
+	predictedR2, err := r.PredictedR2()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if predictedR2 >= r.R2 {
+		t.Errorf("Expected predicted R^2 %.4f to be lower than R^2 %.4f", predictedR2, r.R2)
+	}
+
+	// the shortcut only holds for least squares fits without fixed coefficients, like with LOOCV
+	for _, setup := range []func(r *Regression){
+		func(r *Regression) { r.SetRegularization(L2, 1) },
+		func(r *Regression) { r.SetQuantile(0.5) },
+		func(r *Regression) { r.SetNonNegative(true) },
+		func(r *Regression) { r.FixCoeff(1, 2) },
+	} {
+		fit := r.withData(r.Data)
+		setup(fit)
+		if err := fit.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fit.PRESS(); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+		}
+		if _, err := fit.PredictedR2(); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+		}
+	}
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.DiagnosticsJSON(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x, z := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 3 + 2*x + 0.01*z + math.Cos(5*x), Variables: []float64{x, z}})
+	}
+	r.SetVar(0, "Speed")
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := r.DiagnosticsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Observations int     `json:"observations"`
+		R2           float64 `json:"r2"`
+		Coefficients []struct {
+			Name     string  `json:"name"`
+			Estimate float64 `json:"estimate"`
+			PValue   float64 `json:"p_value"`
+		} `json:"coefficients"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Observations != 20 || report.R2 != r.R2 {
+		t.Errorf("Expected 20 observations and R^2 %v, got %d and %v", r.R2, report.Observations, report.R2)
+	}
+	names := []string{"(Intercept)", "Speed", "x1"}
+	if len(report.Coefficients) != len(names) {
+		t.Fatalf("Expected %d coefficients, got %d", len(names), len(report.Coefficients))
+	}
+	for i, c := range report.Coefficients {
+		if c.Name != names[i] || c.Estimate != r.Coeff(i) {
+			t.Errorf("Expected coefficient %s = %v, got %s = %v", names[i], r.Coeff(i), c.Name, c.Estimate)
+		}
+	}
+	if report.Coefficients[1].PValue > 1e-6 || report.Coefficients[2].PValue < 0.05 {
+		t.Errorf("Expected Speed to be significant and x1 not, got p-values %v and %v", report.Coefficients[1].PValue, report.Coefficients[2].PValue)
+	}
+
+	// the t statistic and p-value of a fixed coefficient are NaN, and encoded as null
+	r.FixCoeff(2, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	data, err = r.DiagnosticsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixed struct {
+		Coefficients []struct {
+			TStat  *float64 `json:"t_stat"`
+			PValue *float64 `json:"p_value"`
+		} `json:"coefficients"`
+	}
+	if err := json.Unmarshal(data, &fixed); err != nil {
+		t.Fatal(err)
+	}
+	if c := fixed.Coefficients[2]; c.TStat != nil || c.PValue != nil {
+		t.Errorf("Expected a null t statistic and p-value for the fixed coefficient, got %s", data)
+	}
+	if c := fixed.Coefficients[1]; c.TStat == nil || c.PValue == nil {
+		t.Errorf("Expected the t statistic and p-value of the free coefficient, got %s", data)
+	}
+
+	// the F statistic of a fit of the offset alone is NaN
+	r = &Regression{}
+	for i := 0; i < 10; i++ {
+		r.Train(DataPoint{Observed: float64(i % 3), Variables: []float64{float64(i)}})
+	}
+	r.FixCoeff(1, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	data, err = r.DiagnosticsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offsetOnly struct {
+		FStatistic *float64 `json:"f_statistic"`
+	}
+	if err := json.Unmarshal(data, &offsetOnly); err != nil {
+		t.Fatal(err)
+	}
+	if offsetOnly.FStatistic != nil {
+		t.Errorf("Expected a null F statistic, got %s", data)
+	}
+}
+
+func TestResidualACF(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.ResidualACF(1); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+
+	// moving average noise, whose autocorrelation is 0.5 at lag 1 and 0 beyond
+	rnd := rand.New(rand.NewSource(1))
+	prev := rnd.NormFloat64()
+	for i := 0; i < 2000; i++ {
+		u := rnd.NormFloat64()
+		x := rnd.Float64() * 10
+		r.Train(DataPoint{Observed: 3 + 2*x + u + prev, Variables: []float64{x}})
+		prev = u
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	acf, err := r.ResidualACF(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acf) != 5 {
+		t.Fatalf("Expected 5 lags, got %v", acf)
+	}
+	if math.Abs(acf[0]-0.5) > 0.05 {
+		t.Errorf("Expected an autocorrelation close to 0.5 at lag 1, got %v", acf[0])
+	}
+	for k, c := range acf[1:] {
+		if math.Abs(c) > 0.1 {
+			t.Errorf("Expected a small autocorrelation at lag %d, got %v", k+2, c)
+		}
+	}
+
+	if _, err := r.ResidualACF(len(r.Data)); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestCriteria(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.Criteria(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x := float64(i) / 4
+		r.Train(DataPoint{Observed: 1 + 2*x + 0.5*x*x + math.Sin(float64(7*i)), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := r.Criteria()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := r.Diagnostics()
+	if math.Abs(c.AIC-d.AIC) > 1e-9 || math.Abs(c.BIC-d.BIC) > 1e-9 {
+		t.Errorf("Expected AIC %v and BIC %v, got %v and %v", d.AIC, d.BIC, c.AIC, c.BIC)
+	}
+	if expected := d.AIC + 2*2*3/17.0; math.Abs(c.AICc-expected) > 1e-9 {
+		t.Errorf("Expected AICc %v, got %v", expected, c.AICc)
+	}
+
+	// the quadratic term is worth its coefficient
+	quadratic := &Regression{}
+	quadratic.Train(r.Data...)
+	quadratic.AddCross(PowCross(0, 2))
+	if err := quadratic.Run(); err != nil {
+		t.Fatal(err)
+	}
+	qc, _ := quadratic.Criteria()
+	if qc.AIC >= c.AIC || qc.AICc >= c.AICc || qc.BIC >= c.BIC {
+		t.Errorf("Expected lower criteria with the quadratic term, got %+v vs %+v", qc, c)
+	}
+}
+
+func TestDurbinWatson(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.DurbinWatson(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+
+	// independent noise, then a random walk
+	rnd := rand.New(rand.NewSource(1))
+	var walk float64
+	correlated := &Regression{}
+	for i := 0; i < 500; i++ {
+		x := rnd.Float64() * 10
+		walk += rnd.NormFloat64()
+		r.Train(DataPoint{Observed: 3 + 2*x + rnd.NormFloat64(), Variables: []float64{x}})
+		correlated.Train(DataPoint{Observed: 3 + 2*x + walk, Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := correlated.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	dw, err := r.DurbinWatson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(dw-2) > 0.2 {
+		t.Errorf("Expected a statistic close to 2 for independent residuals, got %v", dw)
+	}
+	// the statistic is approximately 2(1 - ρ₁)
+	acf, _ := r.ResidualACF(1)
+	if math.Abs(dw-2*(1-acf[0])) > 0.05 {
+		t.Errorf("Expected a statistic close to %v, got %v", 2*(1-acf[0]), dw)
+	}
+	if dw, _ := correlated.DurbinWatson(); dw > 0.5 {
+		t.Errorf("Expected a statistic close to 0 for a random walk, got %v", dw)
+	}
+}
+
+func TestBreuschPagan(t *testing.T) {
+	r := &Regression{}
+	if _, _, err := r.BreuschPagan(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	heteroskedastic := &Regression{}
+	for i := 0; i < 300; i++ {
+		x := rnd.Float64() * 10
+		r.Train(DataPoint{Observed: 3 + 2*x + rnd.NormFloat64(), Variables: []float64{x}})
+		// the noise grows with x
+		heteroskedastic.Train(DataPoint{Observed: 3 + 2*x + x*rnd.NormFloat64(), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := heteroskedastic.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if statistic, pValue, err := r.BreuschPagan(); err != nil || pValue < 0.01 {
+		t.Errorf("Expected a large p-value for a constant variance, got %v (statistic %v, error %v)", pValue, statistic, err)
+	}
+	if statistic, pValue, _ := heteroskedastic.BreuschPagan(); pValue > 1e-6 {
+		t.Errorf("Expected a small p-value for a growing variance, got %v (statistic %v)", pValue, statistic)
+	}
+}
+
+func TestVIF(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.VIF(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		x, z := rnd.NormFloat64(), rnd.NormFloat64()
+		// w is mostly x
+		w := x + 0.1*rnd.NormFloat64()
+		r.Train(DataPoint{Observed: 1 + x + z + w + rnd.NormFloat64(), Variables: []float64{x, z, w}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	vif, err := r.VIF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vif) != 3 {
+		t.Fatalf("Expected 3 factors, got %v", vif)
+	}
+	if vif[0] < 50 || vif[2] < 50 || vif[1] > 1.2 {
+		t.Errorf("Expected large factors for x and w and a factor close to 1 for z, got %v", vif)
+	}
+
+	// check against the definition for z
+	z := &Regression{}
+	for _, d := range r.Data {
+		z.Train(DataPoint{Observed: d.Variables[1], Variables: []float64{d.Variables[0], d.Variables[2]}})
+	}
+	if err := z.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := 1 / (1 - z.R2); math.Abs(vif[1]-expected) > 1e-9 {
+		t.Errorf("Expected a factor of %v for z, got %v", expected, vif[1])
+	}
+
+	// a cross which duplicates a variable is perfectly collinear
+	r.AddCross(PowCross(0, 1))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if vif, _ := r.VIF(); !math.IsInf(vif[0], 1) || !math.IsInf(vif[3], 1) {
+		t.Errorf("Expected infinite factors for duplicated features, got %v", vif)
+	}
+}
+
+func TestInfluence(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.Influence(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 15; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 2 + 3*x + math.Sin(float64(5*i)), Variables: []float64{x}})
+	}
+	// far from the others and off the line
+	r.Train(DataPoint{Observed: 20, Variables: []float64{40}})
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	influence, err := r.Influence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(influence) != 16 {
+		t.Fatalf("Expected 16 data points, got %d", len(influence))
+	}
+	var trace float64
+	for i, inf := range influence {
+		trace += inf.Leverage
+		if i < 15 && (inf.Leverage >= influence[15].Leverage || inf.CooksDistance >= 1) {
+			t.Errorf("Expected data point %d to have little influence, got %+v", i, inf)
+		}
+	}
+	if influence[15].CooksDistance < 1 {
+		t.Errorf("Expected the outlying data point to be influential, got %+v", influence[15])
+	}
+	// the leverages sum to the number of coefficients
+	if math.Abs(trace-2) > 1e-9 {
+		t.Errorf("Expected leverages summing to 2, got %v", trace)
+	}
+
+	// the Cook's distance measures the change of the predictions when refitting without the data point
+	loo := &Regression{}
+	loo.Train(r.Data[:15]...)
+	if err := loo.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var change float64
+	for _, d := range r.Data {
+		predicted, _ := loo.Predict(d.Variables)
+		change += math.Pow(predicted-d.Predicted, 2)
+	}
+	d, _ := r.Diagnostics()
+	if expected := change / (2 * d.ResidualStdError * d.ResidualStdError); math.Abs(influence[15].CooksDistance-expected) > 1e-6 {
+		t.Errorf("Expected a Cook's distance of %v, got %v", expected, influence[15].CooksDistance)
+	}
+
+	// the externally studentized residual is the leave-one-out prediction error over its standard error
+	predicted, stdErr, _ := loo.PredictWithError(r.Data[15].Variables)
+	looD, _ := loo.Diagnostics()
+	expected := (r.Data[15].Observed - predicted) / math.Sqrt(looD.ResidualStdError*looD.ResidualStdError+stdErr*stdErr)
+	if math.Abs(influence[15].ExternallyStudentized-expected) > 1e-6 {
+		t.Errorf("Expected an externally studentized residual of %v, got %v", expected, influence[15].ExternallyStudentized)
+	}
+	if math.Abs(influence[15].ExternallyStudentized) <= math.Abs(influence[15].Studentized) {
+		t.Errorf("Expected the outlier to stand out more once excluded from the variance, got %+v", influence[15])
+	}
+	h := influence[3].Leverage
+	if expected := (r.Data[3].Observed - r.Data[3].Predicted) / (d.ResidualStdError * math.Sqrt(1-h)); math.Abs(influence[3].Studentized-expected) > 1e-9 {
+		t.Errorf("Expected an internally studentized residual of %v, got %v", expected, influence[3].Studentized)
+	}
+}
@@ -0,0 +1,151 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Family describes the distribution of the observed values of a generalized linear model: the link
+// maps their mean μ to the linear predictor η = Xc, and their variance is a function of the mean.
+// Custom families can be defined, the functions must be consistent with each other.
+type Family struct {
+	Name        string
+	Link        func(mu float64) float64  // η as a function of μ
+	InverseLink func(eta float64) float64 // μ as a function of η
+	Derivative  func(eta float64) float64 // dμ/dη
+	Variance    func(mu float64) float64  // variance of the observed values, up to a constant
+	Valid       func(y float64) bool      // whether y is in the domain of the distribution
+	Start       func(y float64) float64   // initial estimate of the mean of an observation y
+}
+
+// minGLMDerivative bounds dmu/deta away from 0, where the fitted means saturate.
+const minGLMDerivative = 1e-10
+
+var (
+	// Gaussian is the family of ordinary least squares, with the identity link.
+	Gaussian = &Family{
+		Name:        "gaussian",
+		Link:        func(mu float64) float64 { return mu },
+		InverseLink: func(eta float64) float64 { return eta },
+		Derivative:  func(eta float64) float64 { return 1 },
+		Variance:    func(mu float64) float64 { return 1 },
+		Valid:       func(y float64) bool { return true },
+		Start:       func(y float64) float64 { return y },
+	}
+	// Poisson is the family of counts, with the log link, so that the coefficients are log rate ratios.
+	Poisson = &Family{
+		Name:        "poisson",
+		Link:        math.Log,
+		InverseLink: math.Exp,
+		Derivative:  math.Exp,
+		Variance:    func(mu float64) float64 { return mu },
+		Valid:       func(y float64) bool { return y >= 0 },
+		Start:       func(y float64) float64 { return y + 0.1 },
+	}
+	// Binomial is the family of proportions and binary outcomes, with the logit link, see SetLogistic.
+	Binomial = &Family{
+		Name:        "binomial",
+		Link:        func(mu float64) float64 { return math.Log(mu / (1 - mu)) },
+		InverseLink: func(eta float64) float64 { return 1 / (1 + math.Exp(-eta)) },
+		Derivative: func(eta float64) float64 {
+			mu := 1 / (1 + math.Exp(-eta))
+			return mu * (1 - mu)
+		},
+		Variance: func(mu float64) float64 { return mu * (1 - mu) },
+		Valid:    func(y float64) bool { return y >= 0 && y <= 1 },
+		Start:    func(y float64) float64 { return (y + 0.5) / 2 },
+	}
+	// Gamma is the family of positive, right-skewed values whose standard deviation is proportional to
+	// their mean, such as durations or amounts. It uses the log link rather than the canonical inverse
+	// one, which keeps the means positive.
+	Gamma = &Family{
+		Name:        "gamma",
+		Link:        math.Log,
+		InverseLink: math.Exp,
+		Derivative:  math.Exp,
+		Variance:    func(mu float64) float64 { return mu * mu },
+		Valid:       func(y float64) bool { return y > 0 },
+		Start:       func(y float64) float64 { return y },
+	}
+)
+
+// SetFamily makes Run fit a generalized linear model of the given family by maximum likelihood, nil
+// restoring ordinary least squares. Predict then returns the mean of the observed values, the inverse
+// link of the linear predictor. It can't be combined with fixed or non-negative coefficients,
+// regularization, quantiles, response transformations, nor with Accumulate. The diagnostics of the
+// residuals, such as Diagnostics, Criteria, DurbinWatson or PRESS, which assume a least squares fit,
+// then return ErrUnsupportedFit.
+func (r *Regression) SetFamily(f *Family) {
+	r.family = f
+}
+
+// SetLogistic makes Run fit a logistic regression of observed values between 0 and 1, typically
+// binary outcomes. Predict then returns the probability 1/(1+exp(-Xc)) of the outcome being 1, while
+// the coefficients are the log-odds ratios of the variables. It is a shorthand for SetFamily(Binomial).
+func (r *Regression) SetLogistic() {
+	r.SetFamily(Binomial)
+}
+
+// checkFamily validates the generalized linear model settings against the training data points.
+func (r *Regression) checkFamily() error {
+	if r.family == nil {
+		return nil
+	}
+	if len(r.fixed) > 0 || r.nonNegative || r.penalty != NoRegularization || r.quantileFit || r.transformed {
+		return ErrUnsupportedFit
+	}
+	for _, d := range r.Data {
+		if !r.family.Valid(d.Observed) {
+			return ErrObservedDomain
+		}
+	}
+	return nil
+}
+
+// solveGLM returns the maximum likelihood coefficients of the generalized linear model, using
+// iteratively reweighted least squares: each iteration fits the linearized response
+// z = η + (y-μ) dη/dμ with weights (dμ/dη)²/V(μ), η and μ being the linear predictor and the mean
+// under the previous coefficients.
+func solveGLM(variables, observed *mat.Dense, weights []float64, f *Family) []float64 {
+	n, p := variables.Dims()
+	eta := make([]float64, n)
+	for i := range eta {
+		eta[i] = f.Link(f.Start(observed.At(i, 0)))
+	}
+
+	var c []float64
+	z := mat.NewDense(n, 1, nil)
+	irls := make([]float64, n)
+	for iter := 0; iter < maxIRLSIterations; iter++ {
+		for i := range irls {
+			mu := f.InverseLink(eta[i])
+			d := math.Max(f.Derivative(eta[i]), minGLMDerivative)
+			z.Set(i, 0, eta[i]+(observed.At(i, 0)-mu)/d)
+			irls[i] = d * d / math.Max(f.Variance(mu), minGLMDerivative)
+			if weights != nil {
+				irls[i] *= weights[i]
+			}
+		}
+		updated := solveNormalEquations(variables, z, irls)
+		if updated == nil {
+			updated = solveConstrained(variables, z, irls, nil)
+		}
+
+		change := math.Inf(1)
+		if c != nil {
+			change = 0
+			for j := range c {
+				change = math.Max(change, math.Abs(updated[j]-c[j]))
+			}
+		}
+		c = updated
+		for i := range eta {
+			eta[i] = mat.Dot(variables.RowView(i), mat.NewVecDense(p, c))
+		}
+		if change < irlsTolerance {
+			break
+		}
+	}
+	return c
+}
@@ -0,0 +1,139 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSetLogistic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := &Regression{}
+	for i := 0; i < 5000; i++ {
+		x, y := rnd.NormFloat64(), rnd.NormFloat64()
+		var outcome float64
+		if rnd.Float64() < 1/(1+math.Exp(-(0.5+2*x-y))) {
+			outcome = 1
+		}
+		r.Train(DataPoint{Observed: outcome, Variables: []float64{x, y}})
+	}
+	r.SetLogistic()
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float64{0.5, 2, -1}
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(c-expected[i]) > 0.15 {
+			t.Errorf("Expected coefficients close to %v, got %v", expected, r.GetCoeffs())
+			break
+		}
+	}
+	p, err := r.Predict([]float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(p-1/(1+math.Exp(-r.Coeff(0)))) > 1e-12 {
+		t.Errorf("Expected the probability of the offset, got %v", p)
+	}
+	for _, d := range r.Data {
+		if d.Predicted <= 0 || d.Predicted >= 1 {
+			t.Fatalf("Expected probabilities, got %v", d.Predicted)
+		}
+	}
+
+	// the residuals of the linear predictor are meaningless for a distribution
+	if _, err := r.Diagnostics(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from Diagnostics, got %v", ErrUnsupportedFit, err)
+	}
+	if _, err := r.Criteria(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from Criteria, got %v", ErrUnsupportedFit, err)
+	}
+	if _, err := r.DurbinWatson(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from DurbinWatson, got %v", ErrUnsupportedFit, err)
+	}
+	if _, err := r.ResidualACF(2); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from ResidualACF, got %v", ErrUnsupportedFit, err)
+	}
+	if _, _, err := r.BreuschPagan(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from BreuschPagan, got %v", ErrUnsupportedFit, err)
+	}
+	if _, _, err := r.ResidualsNormalityTest(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from ResidualsNormalityTest, got %v", ErrUnsupportedFit, err)
+	}
+	if _, err := r.PRESS(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v from PRESS, got %v", ErrUnsupportedFit, err)
+	}
+
+	r.Data[0].Observed = 2
+	if err := r.Run(); err != ErrObservedDomain {
+		t.Errorf("Expected %v, got %v", ErrObservedDomain, err)
+	}
+}
+
+func TestSetFamily(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	poisson := func(mean float64) float64 {
+		var k float64
+		for p := rnd.Float64(); p > math.Exp(-mean); p *= rnd.Float64() {
+			k++
+		}
+		return k
+	}
+	for _, test := range []struct {
+		family *Family
+		sample func(mean float64) float64
+	}{
+		{Gaussian, func(mean float64) float64 { return mean + rnd.NormFloat64() }},
+		{Poisson, poisson},
+		// a gamma distribution of shape 2 is the sum of two exponential ones
+		{Gamma, func(mean float64) float64 { return mean / 2 * (rnd.ExpFloat64() + rnd.ExpFloat64()) }},
+	} {
+		r := &Regression{}
+		for i := 0; i < 5000; i++ {
+			x, y := rnd.Float64(), rnd.Float64()
+			eta := 0.5 + x - 2*y
+			if test.family == Gaussian {
+				eta *= 10
+			}
+			r.Train(DataPoint{Observed: test.sample(test.family.InverseLink(eta)), Variables: []float64{x, y}})
+		}
+		r.SetFamily(test.family)
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []float64{0.5, 1, -2}
+		if test.family == Gaussian {
+			expected = []float64{5, 10, -20}
+			r.SetFamily(nil)
+			if err := r.Run(); err != nil {
+				t.Fatal(err)
+			}
+			ols := r.GetCoeffs()
+			r.SetFamily(Gaussian)
+			if err := r.Run(); err != nil {
+				t.Fatal(err)
+			}
+			for i, c := range r.GetCoeffs() {
+				if math.Abs(c-ols[i]) > 1e-9 {
+					t.Errorf("Expected the gaussian family to match least squares %v, got %v", ols, r.GetCoeffs())
+					break
+				}
+			}
+		}
+		for i, c := range r.GetCoeffs() {
+			if math.Abs(c-expected[i]) > 0.2 {
+				t.Errorf("Expected %s coefficients close to %v, got %v", test.family.Name, expected, r.GetCoeffs())
+				break
+			}
+		}
+	}
+
+	r := &Regression{}
+	r.Train(DataPoints{}.Add(1, 1).Add(0, 2).Add(3, 3).Add(2, 4)...)
+	r.SetFamily(Gamma)
+	if err := r.Run(); err != ErrObservedDomain {
+		t.Errorf("Expected %v, got %v", ErrObservedDomain, err)
+	}
+}
@@ -0,0 +1,55 @@
+package regression
+
+import "gonum.org/v1/gonum/mat"
+
+// SetErrorCovariance makes Run solve a generalized least squares problem, for observations whose
+// errors are correlated, e.g. spatially or in time. cov is the covariance matrix of the errors of the
+// training data points, in training order, up to a constant; a *mat.Cholesky can be passed to reuse
+// an existing factorization. The design is whitened by the inverse of the Cholesky factor of cov, so
+// that the standard errors and the other inference statistics account for the correlations. A nil
+// cov restores ordinary least squares. It can only be combined with fixed coefficients, and requires
+// unweighted data points.
+func (r *Regression) SetErrorCovariance(cov mat.Symmetric) error {
+	if cov == nil {
+		r.errorCov = nil
+		return nil
+	}
+	chol, ok := cov.(*mat.Cholesky)
+	if !ok {
+		chol = new(mat.Cholesky)
+		if !chol.Factorize(cov) {
+			return ErrInvalidCovariance
+		}
+	}
+	r.errorCov = chol
+	return nil
+}
+
+// checkErrorCovariance validates the generalized least squares settings.
+func (r *Regression) checkErrorCovariance() error {
+	if r.errorCov == nil {
+		return nil
+	}
+	if r.nonNegative || r.penalty != NoRegularization || r.quantileFit || r.family != nil || len(r.bounds) > 0 || r.centering {
+		return ErrUnsupportedFit
+	}
+	if r.errorCov.SymmetricDim() != len(r.Data) {
+		return ErrObservationCount
+	}
+	if r.dataWeights() != nil {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// whitenErrors returns L⁻¹variables and L⁻¹observed, L being the Cholesky factor of the covariance of
+// the errors, whose errors are then uncorrelated with a constant variance.
+func (r *Regression) whitenErrors(variables, observed *mat.Dense) (*mat.Dense, *mat.Dense) {
+	var l mat.TriDense
+	r.errorCov.LTo(&l)
+	whiteVariables, whiteObserved := new(mat.Dense), new(mat.Dense)
+	// L is non-singular as cov is positive definite
+	_ = whiteVariables.Solve(&l, variables)
+	_ = whiteObserved.Solve(&l, observed)
+	return whiteVariables, whiteObserved
+}
@@ -0,0 +1,118 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSetErrorCovariance(t *testing.T) {
+	// autoregressive errors, whose covariance is rho^|i-j|
+	const n, rho = 50, 0.8
+	rnd := rand.New(rand.NewSource(1))
+	cov := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			cov.SetSym(i, j, math.Pow(rho, float64(j-i)))
+		}
+	}
+	r := &Regression{}
+	var e float64
+	for i := 0; i < n; i++ {
+		x := rnd.Float64() * 10
+		e = rho*e + math.Sqrt(1-rho*rho)*rnd.NormFloat64()
+		r.Train(DataPoint{Observed: 1 + 2*x + e, Variables: []float64{x}})
+	}
+	if err := r.SetErrorCovariance(cov); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// (XᵀΣ⁻¹X)⁻¹ XᵀΣ⁻¹y
+	variables, observed := r.designMatrix()
+	var inv mat.Dense
+	if err := inv.Inverse(cov); err != nil {
+		t.Fatal(err)
+	}
+	var xtsx, xtsy, tmp mat.Dense
+	tmp.Mul(variables.T(), &inv)
+	xtsx.Mul(&tmp, variables)
+	xtsy.Mul(&tmp, observed)
+	var expected mat.Dense
+	if err := expected.Solve(&xtsx, &xtsy); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(c-expected.At(i, 0)) > 1e-9 {
+			t.Errorf("Expected coefficients %v, got %v", mat.Col(nil, 0, &expected), r.GetCoeffs())
+			break
+		}
+	}
+
+	// R² compares the fit to the generalized least squares fit of the offset alone
+	var coeffs, fitted, resid mat.Dense
+	coeffs.CloneFrom(&expected)
+	fitted.Mul(variables, &coeffs)
+	resid.Sub(observed, &fitted)
+	ones := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i++ {
+		ones.SetVec(i, 1)
+	}
+	y := observed.ColView(0)
+	sse := mat.Inner(resid.ColView(0), &inv, resid.ColView(0))
+	sst := mat.Inner(y, &inv, y) - math.Pow(mat.Inner(ones, &inv, y), 2)/mat.Inner(ones, &inv, ones)
+	d, err := r.Diagnostics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 1 - sse/sst; math.Abs(d.R2-expected) > 1e-9 {
+		t.Errorf("Expected R² %v, got %v", expected, d.R2)
+	}
+
+	// a diagonal covariance gives back ordinary least squares
+	ols := &Regression{}
+	ols.Train(r.Data...)
+	if err := ols.Run(); err != nil {
+		t.Fatal(err)
+	}
+	identity := mat.NewDiagDense(n, nil)
+	for i := 0; i < n; i++ {
+		identity.SetDiag(i, 4)
+	}
+	if err := r.SetErrorCovariance(identity); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(c-ols.Coeff(i)) > 1e-9 {
+			t.Errorf("Expected the least squares coefficients %v, got %v", ols.GetCoeffs(), r.GetCoeffs())
+			break
+		}
+	}
+
+	r.Train(DataPoint{Observed: 1, Variables: []float64{1}})
+	if err := r.Run(); err != ErrObservationCount {
+		t.Errorf("Expected %v, got %v", ErrObservationCount, err)
+	}
+	if err := r.SetErrorCovariance(mat.NewSymDense(2, []float64{1, 2, 2, 1})); err != ErrInvalidCovariance {
+		t.Errorf("Expected %v, got %v", ErrInvalidCovariance, err)
+	}
+
+	// accumulated fits discard the data points the covariance refers to
+	streamed := &Regression{}
+	if err := streamed.SetErrorCovariance(cov); err != nil {
+		t.Fatal(err)
+	}
+	if err := streamed.Accumulate(ols.Data...); err != nil {
+		t.Fatal(err)
+	}
+	if err := streamed.Run(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
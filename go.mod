@@ -3,3 +3,5 @@ module github.com/cocoonspace/regression
 go 1.17
 
 require gonum.org/v1/gonum v0.12.0
+
+require golang.org/x/exp v0.0.0-20191002040644-a1355ae1e2c3 // indirect
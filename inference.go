@@ -0,0 +1,581 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// RobustStdErrors returns the heteroskedasticity-consistent standard errors of the coefficients,
+// using the sandwich estimator (XᵀX)⁻¹ Xᵀ diag(ωᵢ) X (XᵀX)⁻¹. The hcType selects the weights ωᵢ:
+//   - "HC0": eᵢ², as proposed by White,
+//   - "HC1": eᵢ² n/(n-p), correcting for the degrees of freedom,
+//   - "HC2": eᵢ²/(1-hᵢᵢ), which is unbiased when the residuals are homoskedastic,
+//   - "HC3": eᵢ²/(1-hᵢᵢ)², which performs better on small samples.
+//
+// See SetCovarianceEstimator to use them for all the inference on the coefficients. Like the other
+// sandwich estimators, it fails with ErrUnsupportedFit unless the fit is least squares without fixed
+// coefficients.
+func (r *Regression) RobustStdErrors(hcType string) ([]float64, error) {
+	if err := r.checkSandwich(); err != nil {
+		return nil, err
+	}
+	cov, err := r.robustCovariance(hcType)
+	if err != nil {
+		return nil, err
+	}
+	return sqrtDiag(cov), nil
+}
+
+// checkSandwich checks that the regression has run with a fit to which the sandwich estimators of the
+// covariance of the coefficients apply, that is least squares without fixed coefficients.
+func (r *Regression) checkSandwich() error {
+	if err := r.checkInference(); err != nil {
+		return err
+	}
+	if len(r.fixed) > 0 {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// robustCovariance returns the heteroskedasticity-consistent covariance matrix of the coefficients.
+func (r *Regression) robustCovariance(hcType string) (*mat.SymDense, error) {
+	variables, _ := r.whitened()
+	n, p := variables.Dims()
+	residuals := r.residuals()
+	omega := make([]float64, n)
+	switch hcType {
+	case "HC0":
+		for i, e := range residuals {
+			omega[i] = e * e
+		}
+	case "HC1":
+		for i, e := range residuals {
+			omega[i] = e * e * float64(n) / float64(n-p)
+		}
+	case "HC2", "HC3":
+		leverage, err := r.leverage()
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range residuals {
+			omega[i] = e * e / (1 - leverage[i])
+			if hcType == "HC3" {
+				omega[i] /= 1 - leverage[i]
+			}
+		}
+	default:
+		return nil, ErrUnknownEstimator
+	}
+
+	meat := mat.NewSymDense(p, nil)
+	for i, w := range omega {
+		meat.SymRankOne(meat, w, variables.RowView(i))
+	}
+	return r.sandwich(meat)
+}
+
+// SetCovarianceEstimator selects the estimator of the covariance matrix of the coefficients, on which
+// CoeffStdErr, TStat, PValue, CoeffInterval, PredictWithError and PredictWithInterval rely: "HC0" to
+// "HC3" select the heteroskedasticity-consistent estimators of RobustStdErrors, and the empty string,
+// the default, the classical estimator s²(XᵀX)⁻¹, which assumes homoskedastic residuals. The robust
+// estimators do not support fixed coefficients.
+func (r *Regression) SetCovarianceEstimator(estimator string) error {
+	switch estimator {
+	case "", "HC0", "HC1", "HC2", "HC3":
+		r.covEstimator = estimator
+		return nil
+	default:
+		return ErrUnknownEstimator
+	}
+}
+
+// ClusterStdErrors returns the cluster-robust standard errors of the coefficients, for observations
+// which are correlated within groups, e.g. repeated measures of the same subject. groups holds the
+// cluster id of each training data point. The sandwich estimator uses Σ_g X_gᵀ e_g e_gᵀ X_g as its
+// meat, with the G/(G-1) (n-1)/(n-p) small sample correction. It fails with ErrUnsupportedFit unless the
+// fit is least squares without fixed coefficients.
+func (r *Regression) ClusterStdErrors(groups []int) ([]float64, error) {
+	if err := r.checkSandwich(); err != nil {
+		return nil, err
+	}
+	variables, _ := r.whitened()
+	n, p := variables.Dims()
+	if len(groups) != n {
+		return nil, ErrObservationCount
+	}
+
+	scores := map[int]*mat.VecDense{}
+	for i, e := range r.residuals() {
+		score, ok := scores[groups[i]]
+		if !ok {
+			score = mat.NewVecDense(p, nil)
+			scores[groups[i]] = score
+		}
+		score.AddScaledVec(score, e, variables.RowView(i))
+	}
+	g := float64(len(scores))
+	if g < 2 {
+		return nil, ErrNotEnoughData
+	}
+
+	meat := mat.NewSymDense(p, nil)
+	for _, score := range scores {
+		meat.SymRankOne(meat, 1, score)
+	}
+	meat.ScaleSym(g/(g-1)*float64(n-1)/float64(n-p), meat)
+	cov, err := r.sandwich(meat)
+	if err != nil {
+		return nil, err
+	}
+	return sqrtDiag(cov), nil
+}
+
+// NeweyWestStdErrors returns the heteroskedasticity and autocorrelation consistent standard errors of
+// the coefficients proposed by Newey and West, for residuals which are serially correlated, in training
+// order, up to the given number of lags. The sandwich estimator uses
+// Σₜ eₜ² xₜxₜᵀ + Σₗ wₗ Σₜ eₜeₜ₋ₗ (xₜxₜ₋ₗᵀ + xₜ₋ₗxₜᵀ) as its meat, with the Bartlett weights
+// wₗ = 1 - l/(lags+1). A common choice of lags is the integer part of 4(n/100)^(2/9); 0 lags gives the
+// HC0 standard errors. It fails with ErrUnsupportedFit unless the fit is least squares without fixed
+// coefficients.
+func (r *Regression) NeweyWestStdErrors(lags int) ([]float64, error) {
+	if err := r.checkSandwich(); err != nil {
+		return nil, err
+	}
+	variables, _ := r.whitened()
+	n, p := variables.Dims()
+	if lags < 0 || lags >= n {
+		return nil, ErrInvalidIndex
+	}
+
+	residuals := r.residuals()
+	meat := mat.NewSymDense(p, nil)
+	for t, e := range residuals {
+		meat.SymRankOne(meat, e*e, variables.RowView(t))
+	}
+	for l := 1; l <= lags; l++ {
+		w := 1 - float64(l)/float64(lags+1)
+		for t := l; t < n; t++ {
+			meat.RankTwo(meat, w*residuals[t]*residuals[t-l], variables.RowView(t), variables.RowView(t-l))
+		}
+	}
+	cov, err := r.sandwich(meat)
+	if err != nil {
+		return nil, err
+	}
+	return sqrtDiag(cov), nil
+}
+
+// sandwich returns the covariance matrix (XᵀX)⁻¹ meat (XᵀX)⁻¹, meat being symmetric.
+func (r *Regression) sandwich(meat mat.Symmetric) (*mat.SymDense, error) {
+	inv, err := r.inverseCrossProduct()
+	if err != nil {
+		return nil, err
+	}
+	var product mat.Dense
+	product.Product(inv, meat, inv)
+	p := inv.SymmetricDim()
+	cov := mat.NewSymDense(p, nil)
+	for i := 0; i < p; i++ {
+		for j := i; j < p; j++ {
+			// average out the rounding errors which make the product slightly asymmetric
+			cov.SetSym(i, j, (product.At(i, j)+product.At(j, i))/2)
+		}
+	}
+	return cov, nil
+}
+
+// PredictWithError returns the prediction for the inputed features along with its standard error,
+// sqrt(xᵀVx) where V is the covariance matrix of the coefficients, that is the uncertainty of the mean
+// response due to the estimation of the coefficients. V is s²(XᵀX)⁻¹ by default, see
+// SetCovarianceEstimator, and fixed coefficients add no uncertainty. When TransformResponse is used,
+// the standard error is on the transformed scale.
+func (r *Regression) PredictWithError(vars []float64) (prediction, stderr float64, err error) {
+	if err := r.checkInference(); err != nil {
+		return 0, 0, err
+	}
+	if len(vars) != r.numVars {
+		return 0, 0, ErrVariableCount
+	}
+	cov, err := r.coeffCovariance()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	features := r.features(vars)
+	x := mat.NewVecDense(len(features)+1, append([]float64{1}, features...))
+	return r.predict(features), math.Sqrt(mat.Inner(x, cov, x)), nil
+}
+
+// PredictWithInterval returns the prediction for the inputed features along with its prediction
+// interval at the given confidence level, e.g. 0.95, which is expected to contain a new observation.
+// It accounts for both the uncertainty of the coefficients and the variance of the residuals, with a
+// half-width of t s sqrt(1 + xᵀ(XᵀX)⁻¹x). When TransformResponse is used, the interval is computed on
+// the transformed scale and its bounds are transformed back, so that it is no longer symmetric.
+func (r *Regression) PredictWithInterval(vars []float64, confidence float64) (prediction, lo, hi float64, err error) {
+	if confidence <= 0 || confidence >= 1 {
+		return 0, 0, 0, ErrInvalidProbability
+	}
+	if err := r.checkInference(); err != nil {
+		return 0, 0, 0, err
+	}
+	if len(vars) != r.numVars {
+		return 0, 0, 0, ErrVariableCount
+	}
+	cov, err := r.coeffCovariance()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	features := r.features(vars)
+	x := mat.NewVecDense(len(features)+1, append([]float64{1}, features...))
+	fit := r.Coeff(0)
+	for j, val := range features {
+		fit += r.Coeff(j+1) * val
+	}
+	margin := r.tQuantile(confidence) * math.Sqrt(r.residualVariance()+mat.Inner(x, cov, x))
+	return r.inverseResponse(fit), r.inverseResponse(fit - margin), r.inverseResponse(fit + margin), nil
+}
+
+// residualVariance returns the unbiased estimate of the variance of the residuals, SSE divided by the
+// residual degrees of freedom.
+func (r *Regression) residualVariance() float64 {
+	sse, _ := r.sumsOfSquares()
+	return sse / float64(r.residualDF())
+}
+
+// stdErrors returns the classical standard errors of the coefficients, assuming homoskedastic residuals.
+func (r *Regression) stdErrors() ([]float64, error) {
+	cov, err := r.coeffCovariance()
+	if err != nil {
+		return nil, err
+	}
+	return sqrtDiag(cov), nil
+}
+
+// coeffCovariance returns the covariance matrix of the coefficients, s²(XᵀX)⁻¹, assuming homoskedastic
+// residuals. Fixed coefficients have no variance, the others being computed on the free columns of X.
+func (r *Regression) coeffCovariance() (*mat.SymDense, error) {
+	if r.covEstimator != "" {
+		if err := r.checkSandwich(); err != nil {
+			return nil, err
+		}
+		return r.robustCovariance(r.covEstimator)
+	}
+	if len(r.fixed) == 0 {
+		inv, err := r.inverseCrossProduct()
+		if err != nil {
+			return nil, err
+		}
+		cov := new(mat.SymDense)
+		cov.ScaleSym(r.residualVariance(), inv)
+		return cov, nil
+	}
+
+	variables, _ := r.whitened()
+	n, p := variables.Dims()
+	var free []int
+	for j := 0; j < p; j++ {
+		if _, ok := r.fixed[j]; !ok {
+			free = append(free, j)
+		}
+	}
+	reduced := mat.NewDense(n, len(free), nil)
+	for k, j := range free {
+		reduced.SetCol(k, mat.Col(nil, j, variables))
+	}
+	xtx := mat.NewSymDense(len(free), nil)
+	xtx.SymOuterK(1, reduced.T())
+	var chol mat.Cholesky
+	if !chol.Factorize(xtx) {
+		return nil, ErrSingularMatrix
+	}
+	inv := new(mat.SymDense)
+	if err := chol.InverseTo(inv); err != nil {
+		return nil, ErrSingularMatrix
+	}
+	var sse float64
+	for _, e := range r.residuals() {
+		sse += e * e
+	}
+	s2 := sse / float64(r.residualDF())
+
+	cov := mat.NewSymDense(p, nil)
+	for k, j := range free {
+		for l := k; l < len(free); l++ {
+			cov.SetSym(j, free[l], s2*inv.At(k, l))
+		}
+	}
+	return cov, nil
+}
+
+// residualDF returns the residual degrees of freedom of the last run, the number of observations minus
+// the number of fitted coefficients.
+func (r *Regression) residualDF() int {
+	n, p := r.variables.Dims()
+	return n - p + len(r.fixed)
+}
+
+// checkInference checks that the regression has run with a fit to which the classical inference
+// statistics apply, that is least squares, possibly weighted, generalized or with fixed coefficients.
+func (r *Regression) checkInference() error {
+	if err := r.requireData(); err != nil {
+		return err
+	}
+	if !r.leastSquares() && r.errorCov == nil {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// CoeffCovariance returns the covariance matrix of the coefficients, in the order of GetCoeffs, from
+// the estimator selected with SetCovarianceEstimator, by default s²(XᵀX)⁻¹ which assumes homoskedastic
+// residuals. Fixed coefficients have no variance nor covariance. The matrix is a copy which the caller
+// may modify, e.g. to run Wald tests or to propagate the uncertainty of the coefficients.
+func (r *Regression) CoeffCovariance() (*mat.SymDense, error) {
+	if err := r.checkInference(); err != nil {
+		return nil, err
+	}
+	return r.coeffCovariance()
+}
+
+// CoeffStdErr returns the standard error of coefficient i, the square root of the diagonal of
+// CoeffCovariance. Index 0 is the offset, and the following indices are the variables then the feature
+// crosses. Fixed coefficients have a standard error of 0.
+func (r *Regression) CoeffStdErr(i int) (float64, error) {
+	if err := r.checkInference(); err != nil {
+		return 0, err
+	}
+	if i < 0 || i >= len(r.coeff) {
+		return 0, ErrInvalidIndex
+	}
+	stdErrors, err := r.stdErrors()
+	if err != nil {
+		return 0, err
+	}
+	return stdErrors[i], nil
+}
+
+// TStat returns the t statistic of coefficient i, its estimate divided by its standard error, which
+// measures how far from 0 it is relative to its uncertainty. Fixed coefficients have a NaN t statistic.
+func (r *Regression) TStat(i int) (float64, error) {
+	stdErr, err := r.CoeffStdErr(i)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := r.fixed[i]; ok {
+		return math.NaN(), nil
+	}
+	return r.Coeff(i) / stdErr, nil
+}
+
+// CoeffInterval returns the confidence interval of coefficient i at the given confidence level, e.g.
+// 0.95, from the t distribution with the residual degrees of freedom. A fixed coefficient has an empty
+// interval around its value.
+func (r *Regression) CoeffInterval(i int, confidence float64) (lo, hi float64, err error) {
+	if confidence <= 0 || confidence >= 1 {
+		return 0, 0, ErrInvalidProbability
+	}
+	stdErr, err := r.CoeffStdErr(i)
+	if err != nil {
+		return 0, 0, err
+	}
+	margin := r.tQuantile(confidence) * stdErr
+	return r.Coeff(i) - margin, r.Coeff(i) + margin, nil
+}
+
+// tQuantile returns the critical value of the two-sided t test at the given confidence level, with the
+// residual degrees of freedom of the last run.
+func (r *Regression) tQuantile(confidence float64) float64 {
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(r.residualDF())}
+	return dist.Quantile(1 - (1-confidence)/2)
+}
+
+// inverseCrossProduct returns (XᵀX)⁻¹ for the design matrix of the last run, computing it on first use.
+func (r *Regression) inverseCrossProduct() (*mat.SymDense, error) {
+	if r.xtxInv != nil {
+		return r.xtxInv, nil
+	}
+	variables, _ := r.whitened()
+	_, p := variables.Dims()
+	xtx := mat.NewSymDense(p, nil)
+	xtx.SymOuterK(1, variables.T())
+
+	var chol mat.Cholesky
+	if !chol.Factorize(xtx) {
+		return nil, ErrSingularMatrix
+	}
+	inv := new(mat.SymDense)
+	if err := chol.InverseTo(inv); err != nil {
+		return nil, ErrSingularMatrix
+	}
+	r.xtxInv = inv
+	return inv, nil
+}
+
+// whitened returns the design matrix and observations of the last run, with rows scaled by the square
+// root of their weight, so that a weighted fit can be analysed as an ordinary one.
+func (r *Regression) whitened() (*mat.Dense, *mat.Dense) {
+	if r.weights == nil {
+		return r.variables, r.observed
+	}
+	return weightRows(r.variables, r.weights), weightRows(r.observed, r.weights)
+}
+
+// residuals returns the residuals of the last run, on the scale of the fit and scaled by the square
+// root of the weights.
+func (r *Regression) residuals() []float64 {
+	variables, observed := r.whitened()
+	n, p := variables.Dims()
+	residuals := make([]float64, n)
+	for i := range residuals {
+		residuals[i] = observed.At(i, 0)
+		for j := 0; j < p; j++ {
+			residuals[i] -= r.coeff[j] * variables.At(i, j)
+		}
+	}
+	return residuals
+}
+
+// leverage returns the diagonal of the hat matrix X (XᵀX)⁻¹ Xᵀ.
+func (r *Regression) leverage() ([]float64, error) {
+	inv, err := r.inverseCrossProduct()
+	if err != nil {
+		return nil, err
+	}
+	variables, _ := r.whitened()
+	n, p := variables.Dims()
+	leverage := make([]float64, n)
+	tmp := mat.NewVecDense(p, nil)
+	for i := range leverage {
+		row := variables.RowView(i)
+		tmp.MulVec(inv, row)
+		leverage[i] = mat.Dot(row, tmp)
+	}
+	return leverage, nil
+}
+
+// sqrtDiag returns the square roots of the diagonal elements of m.
+func sqrtDiag(m mat.Matrix) []float64 {
+	n, _ := m.Dims()
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = math.Sqrt(m.At(i, i))
+	}
+	return d
+}
+
+// R2ConfidenceInterval returns the 1-alpha confidence interval of R2, using the Fisher transformation
+// of the multiple correlation coefficient R: atanh(R) is approximately normal with a standard error of
+// 1/sqrt(n-3). This approximation is best when the number of observations is large compared to the
+// number of variables.
+func (r *Regression) R2ConfidenceInterval(alpha float64) (lower, upper float64, err error) {
+	if err := r.requireData(); err != nil {
+		return 0, 0, err
+	}
+	if alpha <= 0 || alpha >= 1 {
+		return 0, 0, ErrInvalidProbability
+	}
+	n, _ := r.variables.Dims()
+	if n <= 3 {
+		return 0, 0, ErrNotEnoughData
+	}
+
+	z := math.Atanh(math.Sqrt(math.Max(r.R2, 0)))
+	margin := distuv.UnitNormal.Quantile(1-alpha/2) / math.Sqrt(float64(n-3))
+	lo, hi := math.Tanh(z-margin), math.Tanh(z+margin)
+	return math.Pow(math.Max(lo, 0), 2), hi * hi, nil
+}
+
+// PValue returns the p-value of the two-sided t test of coefficient i being 0, under the assumption of
+// normal homoskedastic residuals. A small p-value, e.g. below 0.05, means that the variable matters.
+// Fixed coefficients have a NaN p-value.
+func (r *Regression) PValue(i int) (float64, error) {
+	t, err := r.TStat(i)
+	if err != nil {
+		return 0, err
+	}
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(r.residualDF())}
+	return 2 * dist.Survival(math.Abs(t)), nil
+}
+
+// ModelPValue returns the p-value of the F test of the regression, whose null hypothesis is that all
+// the coefficients but the offset are 0. A large p-value means that the fit is indistinguishable from
+// the mean of the observed values.
+func (r *Regression) ModelPValue() (float64, error) {
+	test, err := r.FTest()
+	if err != nil {
+		return 0, err
+	}
+	return test.PValue, nil
+}
+
+// FTestResult holds the outcome of the F test of the overall significance of a regression.
+type FTestResult struct {
+	F      float64 // ((SST-SSE)/DF1)/(SSE/DF2)
+	DF1    int     // number of fitted coefficients, excluding the offset
+	DF2    int     // residual degrees of freedom
+	PValue float64 // probability of an F at least as large if all the coefficients but the offset were 0
+}
+
+// FTest returns the F statistic of the regression along with its degrees of freedom and p-value.
+// Fixed coefficients other than the offset are not counted in the degrees of freedom. It fails with
+// ErrUnsupportedFit when no coefficient but the offset is fitted, as there is nothing to test.
+func (r *Regression) FTest() (FTestResult, error) {
+	if err := r.checkInference(); err != nil {
+		return FTestResult{}, err
+	}
+	_, p := r.variables.Dims()
+	test := FTestResult{DF1: p - 1, DF2: r.residualDF()}
+	for i := range r.fixed {
+		if i > 0 {
+			test.DF1--
+		}
+	}
+	if test.DF1 < 1 {
+		return FTestResult{}, ErrUnsupportedFit
+	}
+	sse, sst := r.sumsOfSquares()
+	test.F = ((sst - sse) / float64(test.DF1)) / (sse / float64(test.DF2))
+	test.PValue = distuv.F{D1: float64(test.DF1), D2: float64(test.DF2)}.Survival(test.F)
+	return test, nil
+}
+
+// ANOVASource is a row of an analysis of variance table.
+type ANOVASource struct {
+	DF int     // degrees of freedom
+	SS float64 // sum of squares
+	MS float64 // mean square, SS/DF
+}
+
+// ANOVATable is the analysis of variance of a regression, which decomposes the total sum of squares of
+// the observed values around their mean into the sum of squares explained by the regression and the
+// residual sum of squares.
+type ANOVATable struct {
+	Regression ANOVASource
+	Residual   ANOVASource
+	Total      ANOVASource
+	F          float64 // ratio of the regression and residual mean squares
+	PValue     float64 // p-value of the F test
+}
+
+// ANOVA returns the analysis of variance table of the regression, on the scale of the fit.
+func (r *Regression) ANOVA() (ANOVATable, error) {
+	test, err := r.FTest()
+	if err != nil {
+		return ANOVATable{}, err
+	}
+	sse, sst := r.sumsOfSquares()
+	source := func(df int, ss float64) ANOVASource {
+		return ANOVASource{DF: df, SS: ss, MS: ss / float64(df)}
+	}
+	return ANOVATable{
+		Regression: source(test.DF1, sst-sse),
+		Residual:   source(test.DF2, sse),
+		Total:      source(test.DF1+test.DF2, sst),
+		F:          test.F,
+		PValue:     test.PValue,
+	}, nil
+}
@@ -0,0 +1,678 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestRobustStdErrors(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.RobustStdErrors("HC0"); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	// the noise grows with x
+	for i := 1; i <= 60; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 1 + 2*x + 0.5*x*math.Sin(1.7*x), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	classical, err := r.stdErrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc0, err := r.RobustStdErrors("HC0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc0[1] <= classical[1] {
+		t.Errorf("Expected the HC0 slope error %.4f to exceed the classical one %.4f", hc0[1], classical[1])
+	}
+
+	hc1, _ := r.RobustStdErrors("HC1")
+	hc3, _ := r.RobustStdErrors("HC3")
+	if hc1[1] <= hc0[1] || hc3[1] <= hc0[1] {
+		t.Errorf("Expected HC1 %.4f and HC3 %.4f to exceed HC0 %.4f", hc1[1], hc3[1], hc0[1])
+	}
+
+	hc2, _ := r.RobustStdErrors("HC2")
+	if hc2[1] <= hc0[1] || hc2[1] >= hc3[1] {
+		t.Errorf("Expected HC2 %.4f between HC0 %.4f and HC3 %.4f", hc2[1], hc0[1], hc3[1])
+	}
+
+	if _, err := r.RobustStdErrors("HC9"); err != ErrUnknownEstimator {
+		t.Errorf("Expected %v, got %v", ErrUnknownEstimator, err)
+	}
+}
+
+func TestSetCovarianceEstimator(t *testing.T) {
+	r := &Regression{}
+	for i := 1; i <= 60; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 1 + 2*x + 0.5*x*math.Sin(1.7*x), Variables: []float64{x}})
+	}
+	if err := r.SetCovarianceEstimator("HC9"); err != ErrUnknownEstimator {
+		t.Errorf("Expected %v, got %v", ErrUnknownEstimator, err)
+	}
+	if err := r.SetCovarianceEstimator("HC3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	hc3, _ := r.RobustStdErrors("HC3")
+	stdErr, err := r.CoeffStdErr(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(stdErr-hc3[1]) > 1e-12 {
+		t.Errorf("Expected the HC3 standard error %v, got %v", hc3[1], stdErr)
+	}
+	if tstat, _ := r.TStat(1); math.Abs(tstat-r.Coeff(1)/hc3[1]) > 1e-9 {
+		t.Errorf("Expected a t statistic of %v, got %v", r.Coeff(1)/hc3[1], tstat)
+	}
+
+	r.FixCoeff(0, 1)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CoeffStdErr(1); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestR2ConfidenceInterval(t *testing.T) {
+	r := &Regression{}
+	if _, _, err := r.R2ConfidenceInterval(0.05); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 40; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: x + 8*math.Sin(2*x), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	lower, upper, err := r.R2ConfidenceInterval(0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lower > r.R2 || upper < r.R2 || lower < 0 || upper > 1 {
+		t.Errorf("Expected R^2 %.4f within [%.4f, %.4f]", r.R2, lower, upper)
+	}
+
+	narrowLower, narrowUpper, _ := r.R2ConfidenceInterval(0.5)
+	if narrowLower < lower || narrowUpper > upper {
+		t.Errorf("Expected the 50%% interval [%.4f, %.4f] within the 95%% one [%.4f, %.4f]", narrowLower, narrowUpper, lower, upper)
+	}
+
+	if _, _, err := r.R2ConfidenceInterval(1); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+}
+
+func TestClusterStdErrors(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.ClusterStdErrors(nil); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	// 6 subjects measured 10 times, each subject having its own shift
+	var groups []int
+	for s := 0; s < 6; s++ {
+		shift := 5 * math.Sin(float64(3*s+1))
+		for m := 0; m < 10; m++ {
+			x := float64(s) + float64(m)/10
+			r.Train(DataPoint{Observed: 1 + 2*x + shift + 0.1*math.Cos(float64(7*m)), Variables: []float64{x}})
+			groups = append(groups, s)
+		}
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	classical, err := r.stdErrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clustered, err := r.ClusterStdErrors(groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clustered[1] <= classical[1] {
+		t.Errorf("Expected the clustered slope error %.4f to exceed the classical one %.4f", clustered[1], classical[1])
+	}
+
+	if _, err := r.ClusterStdErrors(groups[1:]); err != ErrObservationCount {
+		t.Errorf("Expected %v, got %v", ErrObservationCount, err)
+	}
+}
+
+func TestPredictWithError(t *testing.T) {
+	r := &Regression{}
+	if _, _, err := r.PredictWithError([]float64{1}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 30; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 3 + 2*x + 2*math.Sin(3*x), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	center, centerErr, err := r.PredictWithError([]float64{14.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := r.Predict([]float64{14.5}); center != expected {
+		t.Errorf("Expected prediction %v, got %v", expected, center)
+	}
+	// at the mean of x, the error is the one of the offset of the centered model: s/sqrt(n)
+	d, _ := r.Diagnostics()
+	if expected := d.ResidualStdError / math.Sqrt(30); math.Abs(centerErr-expected) > 1e-9 {
+		t.Errorf("Expected standard error %v, got %v", expected, centerErr)
+	}
+
+	_, farErr, _ := r.PredictWithError([]float64{60})
+	if farErr <= centerErr {
+		t.Errorf("Expected the error to grow away from the data, got %v vs %v", farErr, centerErr)
+	}
+	if _, _, err := r.PredictWithError([]float64{1, 2}); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+
+	// the robust estimator applies to the mean response too
+	if err := r.SetCovarianceEstimator("HC3"); err != nil {
+		t.Fatal(err)
+	}
+	cov, err := r.CoeffCovariance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, robustErr, err := r.PredictWithError([]float64{60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := mat.NewVecDense(2, []float64{1, 60})
+	if expected := math.Sqrt(mat.Inner(x, cov, x)); math.Abs(robustErr-expected) > 1e-9 {
+		t.Errorf("Expected the robust standard error %v, got %v", expected, robustErr)
+	}
+	if err := r.SetCovarianceEstimator(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fixed slope leaves the error of the offset only
+	r.FixCoeff(1, 2)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	offsetErr, _ := r.CoeffStdErr(0)
+	if _, fixedErr, _ := r.PredictWithError([]float64{60}); math.Abs(fixedErr-offsetErr) > 1e-9 {
+		t.Errorf("Expected the standard error of the offset %v, got %v", offsetErr, fixedErr)
+	}
+
+	ridge := &Regression{}
+	ridge.Train(r.Data...)
+	ridge.SetRegularization(L2, 1)
+	if err := ridge.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ridge.PredictWithError([]float64{1}); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestCoeffStdErr(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.CoeffStdErr(0); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + 2*x + 0.1*y + math.Cos(float64(3*i)), Variables: []float64{x, y}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// s²(XᵀX)⁻¹ computed directly
+	var xtx, inv mat.Dense
+	xtx.Mul(r.variables.T(), r.variables)
+	if err := inv.Inverse(&xtx); err != nil {
+		t.Fatal(err)
+	}
+	var sse float64
+	for _, d := range r.Data {
+		sse += d.Error * d.Error
+	}
+	for i := 0; i < 3; i++ {
+		stdErr, err := r.CoeffStdErr(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := math.Sqrt(sse / 17 * inv.At(i, i))
+		if math.Abs(stdErr-expected) > 1e-9 {
+			t.Errorf("Expected the standard error of coefficient %d to be %v, got %v", i, expected, stdErr)
+		}
+		tstat, err := r.TStat(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(tstat-r.Coeff(i)/expected) > 1e-6 {
+			t.Errorf("Expected the t statistic of coefficient %d to be %v, got %v", i, r.Coeff(i)/expected, tstat)
+		}
+	}
+	if tstat, _ := r.TStat(1); tstat < 10 {
+		t.Errorf("Expected a significant slope, got a t statistic of %v", tstat)
+	}
+	if tstat, _ := r.TStat(2); math.Abs(tstat) > 3 {
+		t.Errorf("Expected an insignificant coefficient, got a t statistic of %v", tstat)
+	}
+
+	// a fixed coefficient has no uncertainty, and the others are those of the reduced model
+	r.FixCoeff(2, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	reduced := &Regression{}
+	for _, d := range r.Data {
+		reduced.Train(DataPoint{Observed: d.Observed, Variables: d.Variables[:1]})
+	}
+	if err := reduced.Run(); err != nil {
+		t.Fatal(err)
+	}
+	fixedErr, _ := r.CoeffStdErr(2)
+	slopeErr, _ := r.CoeffStdErr(1)
+	expected, _ := reduced.CoeffStdErr(1)
+	if fixedErr != 0 || math.Abs(slopeErr-expected) > 1e-9 {
+		t.Errorf("Expected standard errors 0 and %v, got %v and %v", expected, fixedErr, slopeErr)
+	}
+
+	if _, err := r.CoeffStdErr(3); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestPValue(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.ModelPValue(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 30; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + 0.5*x + 0.1*y + 2*math.Cos(float64(3*i)), Variables: []float64{x, y}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// p-values match those reported by DiagnosticsJSON
+	reports, err := r.coeffReports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, report := range reports {
+		p, err := r.PValue(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(p-report.PValue) > 1e-12 {
+			t.Errorf("Expected p-value %v for coefficient %d, got %v", report.PValue, i, p)
+		}
+	}
+	if p, _ := r.PValue(1); p > 1e-6 {
+		t.Errorf("Expected a significant slope, got a p-value of %v", p)
+	}
+	if p, _ := r.PValue(2); p < 0.05 {
+		t.Errorf("Expected an insignificant coefficient, got a p-value of %v", p)
+	}
+
+	p, err := r.ModelPValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p > 1e-6 {
+		t.Errorf("Expected a significant model, got a p-value of %v", p)
+	}
+
+	noise := &Regression{}
+	for i := 0; i < 30; i++ {
+		noise.Train(DataPoint{Observed: math.Cos(float64(3 * i)), Variables: []float64{math.Sin(float64(5 * i))}})
+	}
+	if err := noise.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := noise.ModelPValue(); p < 0.05 {
+		t.Errorf("Expected an insignificant model, got a p-value of %v", p)
+	}
+}
+
+func TestCoeffInterval(t *testing.T) {
+	r := &Regression{}
+	if _, _, err := r.CoeffInterval(0, 0.95); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 25; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 4 + 1.5*x + math.Sin(float64(7*i)), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	lo, hi, err := r.CoeffInterval(1, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lo > 1.5 || hi < 1.5 {
+		t.Errorf("Expected the true slope 1.5 within [%v, %v]", lo, hi)
+	}
+	// the bounds are the estimate plus or minus t(0.975, 23) standard errors
+	stdErr, _ := r.CoeffStdErr(1)
+	if expected := r.Coeff(1) + 2.068658*stdErr; math.Abs(hi-expected) > 1e-6 {
+		t.Errorf("Expected upper bound %v, got %v", expected, hi)
+	}
+	if math.Abs((lo+hi)/2-r.Coeff(1)) > 1e-12 {
+		t.Errorf("Expected an interval centered on %v, got [%v, %v]", r.Coeff(1), lo, hi)
+	}
+
+	narrowLo, narrowHi, _ := r.CoeffInterval(1, 0.5)
+	if narrowLo < lo || narrowHi > hi {
+		t.Errorf("Expected the 50%% interval [%v, %v] within the 95%% one [%v, %v]", narrowLo, narrowHi, lo, hi)
+	}
+
+	if _, _, err := r.CoeffInterval(1, 1); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+	if _, _, err := r.CoeffInterval(2, 0.95); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestPredictWithInterval(t *testing.T) {
+	r := &Regression{}
+	if _, _, _, err := r.PredictWithInterval([]float64{1}, 0.95); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 30; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 3 + 2*x + 2*math.Sin(3*x), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	prediction, lo, hi, err := r.PredictWithInterval([]float64{14.5}, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := r.Predict([]float64{14.5}); prediction != expected {
+		t.Errorf("Expected prediction %v, got %v", expected, prediction)
+	}
+	// the prediction interval combines the residual variance and the error of the mean response
+	_, meanErr, _ := r.PredictWithError([]float64{14.5})
+	d, _ := r.Diagnostics()
+	margin := 2.048407 * math.Sqrt(d.ResidualStdError*d.ResidualStdError+meanErr*meanErr)
+	if math.Abs(lo-(prediction-margin)) > 1e-5 || math.Abs(hi-(prediction+margin)) > 1e-5 {
+		t.Errorf("Expected interval [%v, %v], got [%v, %v]", prediction-margin, prediction+margin, lo, hi)
+	}
+
+	// about 95% of the observations fall within their interval
+	var inside int
+	for _, p := range r.Data {
+		_, lo, hi, _ := r.PredictWithInterval(p.Variables, 0.95)
+		if p.Observed >= lo && p.Observed <= hi {
+			inside++
+		}
+	}
+	if inside < 26 {
+		t.Errorf("Expected most observations within their prediction interval, got %d out of 30", inside)
+	}
+
+	_, farLo, farHi, _ := r.PredictWithInterval([]float64{60}, 0.95)
+	if farHi-farLo <= hi-lo {
+		t.Errorf("Expected the interval to widen away from the data, got %v vs %v", farHi-farLo, hi-lo)
+	}
+
+	if _, _, _, err := r.PredictWithInterval(nil, 0.95); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+	if _, _, _, err := r.PredictWithInterval([]float64{1}, 0); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+}
+
+func TestFTest(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.FTest(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(MakeDataPoints([][]float64{
+		{1, 1, 4},
+		{3, 2, 3},
+		{4, 3, 5},
+		{8, 4, 1},
+		{9, 5, 2},
+		{12, 6, 6},
+	}, 0)...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	test, err := r.FTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := r.Diagnostics()
+	if test.DF1 != 2 || test.DF2 != 3 || math.Abs(test.F-d.FStatistic) > 1e-9 {
+		t.Errorf("Expected F(2, 3) = %v, got F(%d, %d) = %v", d.FStatistic, test.DF1, test.DF2, test.F)
+	}
+	if expected := (distuv.F{D1: 2, D2: 3}).Survival(d.FStatistic); math.Abs(test.PValue-expected) > 1e-12 {
+		t.Errorf("Expected p-value %v, got %v", expected, test.PValue)
+	}
+
+	// a fixed coefficient is not a degree of freedom of the model
+	r.FixCoeff(2, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	test, _ = r.FTest()
+	if test.DF1 != 1 || test.DF2 != 4 {
+		t.Errorf("Expected F(1, 4), got F(%d, %d)", test.DF1, test.DF2)
+	}
+
+	// fixing the offset leaves the slopes to test
+	r.FixCoeff(0, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if test, _ = r.FTest(); test.DF1 != 1 || test.DF2 != 5 {
+		t.Errorf("Expected F(1, 5), got F(%d, %d)", test.DF1, test.DF2)
+	}
+
+	// with every slope fixed, there is nothing to test
+	r.FixCoeff(1, 2)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.FTest(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+	if _, err := r.ModelPValue(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+	if _, err := r.ANOVA(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestANOVA(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.ANOVA(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(
+		DataPoint{Observed: 2, Variables: []float64{1}},
+		DataPoint{Observed: 5, Variables: []float64{2}},
+		DataPoint{Observed: 5, Variables: []float64{3}},
+		DataPoint{Observed: 8, Variables: []float64{4}},
+	)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fit is 0.5 + 1.8x, with residuals -0.3, 0.9, -0.9 and 0.3 around a mean of 5
+	table, err := r.ANOVA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ANOVATable{
+		Regression: ANOVASource{DF: 1, SS: 16.2, MS: 16.2},
+		Residual:   ANOVASource{DF: 2, SS: 1.8, MS: 0.9},
+		Total:      ANOVASource{DF: 3, SS: 18, MS: 6},
+		F:          18,
+	}
+	for _, row := range []struct {
+		name     string
+		got, exp ANOVASource
+	}{
+		{"regression", table.Regression, expected.Regression},
+		{"residual", table.Residual, expected.Residual},
+		{"total", table.Total, expected.Total},
+	} {
+		if row.got.DF != row.exp.DF || math.Abs(row.got.SS-row.exp.SS) > 1e-9 || math.Abs(row.got.MS-row.exp.MS) > 1e-9 {
+			t.Errorf("Expected %s row %+v, got %+v", row.name, row.exp, row.got)
+		}
+	}
+	if math.Abs(table.F-expected.F) > 1e-9 {
+		t.Errorf("Expected F %v, got %v", expected.F, table.F)
+	}
+	if p, _ := r.ModelPValue(); table.PValue != p {
+		t.Errorf("Expected p-value %v, got %v", p, table.PValue)
+	}
+}
+
+func TestNeweyWestStdErrors(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.NeweyWestStdErrors(1); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	// a trending variable with autocorrelated noise
+	rnd := rand.New(rand.NewSource(1))
+	var noise float64
+	for i := 0; i < 400; i++ {
+		x := float64(i)/40 + rnd.NormFloat64()
+		noise = 0.9*noise + rnd.NormFloat64()
+		r.Train(DataPoint{Observed: 1 + 2*x + noise, Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	hc0, _ := r.RobustStdErrors("HC0")
+	lag0, err := r.NeweyWestStdErrors(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range hc0 {
+		if math.Abs(lag0[i]-hc0[i]) > 1e-12 {
+			t.Errorf("Expected 0 lags to give the HC0 standard errors %v, got %v", hc0, lag0)
+		}
+	}
+
+	hac, err := r.NeweyWestStdErrors(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hac[1] < 1.5*hc0[1] {
+		t.Errorf("Expected the autocorrelation to inflate the slope error %v well beyond %v", hac[1], hc0[1])
+	}
+
+	if _, err := r.NeweyWestStdErrors(-1); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestSandwichUnsupported(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var data []DataPoint
+	for i := 0; i < 100; i++ {
+		x := rnd.NormFloat64()
+		var outcome float64
+		if rnd.Float64() < 1/(1+math.Exp(-2*x)) {
+			outcome = 1
+		}
+		data = append(data, DataPoint{Observed: outcome, Variables: []float64{x}})
+	}
+	logistic := &Regression{}
+	logistic.Train(data...)
+	logistic.SetLogistic()
+	fixed := &Regression{}
+	fixed.Train(data...)
+	fixed.FixCoeff(0, 0.5)
+	groups := make([]int, len(data))
+	for i := range groups {
+		groups[i] = i % 10
+	}
+
+	for _, r := range []*Regression{logistic, fixed} {
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.RobustStdErrors("HC0"); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+		}
+		if _, err := r.ClusterStdErrors(groups); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+		}
+		if _, err := r.NeweyWestStdErrors(2); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+		}
+	}
+}
+
+func TestCoeffCovariance(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.CoeffCovariance(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + 2*x + y + math.Cos(float64(3*i)), Variables: []float64{x, y}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	cov, err := r.CoeffCovariance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cov.SymmetricDim() != 3 {
+		t.Fatalf("Expected a 3x3 matrix, got %d", cov.SymmetricDim())
+	}
+	for i := 0; i < 3; i++ {
+		stdErr, _ := r.CoeffStdErr(i)
+		if math.Abs(math.Sqrt(cov.At(i, i))-stdErr) > 1e-12 {
+			t.Errorf("Expected the variance of coefficient %d to be %v, got %v", i, stdErr*stdErr, cov.At(i, i))
+		}
+	}
+	// the offset and the slope of a positive variable are negatively correlated
+	if cov.At(0, 1) >= 0 {
+		t.Errorf("Expected a negative covariance of the offset and the slope, got %v", cov.At(0, 1))
+	}
+
+	// the caller owns the matrix
+	cov.SetSym(1, 1, 0)
+	if stdErr, _ := r.CoeffStdErr(1); stdErr == 0 {
+		t.Error("Expected the covariance matrix to be a copy")
+	}
+}
@@ -0,0 +1,69 @@
+package regression
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonField returns the number at path in a decoded JSON record, the path being a field name, or field
+// names separated by dots to reach into nested objects, e.g. "user.age".
+func jsonField(record map[string]interface{}, path string) (float64, bool) {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		nested, ok := record[name].(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		record = nested
+	}
+	v, ok := record[names[len(names)-1]].(float64)
+	return v, ok
+}
+
+// TrainJSONLines adds newline-delimited JSON records, one object per line, as training data points.
+// The observedField holds the observed values, and variable i is read from varFields[i], the fields
+// being named after the variables and the observed value, see SetObserved and SetVar. A field can be
+// nested in objects, its path being the field names separated by dots, e.g. "user.age". The other
+// fields of the records are ignored. It fails with ErrInvalidField if a field is missing or is not a
+// number, or with the decoding error of the first invalid record, in which case no data point is
+// added. The regression must then be run.
+func (r *Regression) TrainJSONLines(rd io.Reader, observedField string, varFields ...string) error {
+	dec := json.NewDecoder(rd)
+	var data []DataPoint
+	for n := 1; ; n++ {
+		var record map[string]interface{}
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		field := func(path string) (float64, error) {
+			v, ok := jsonField(record, path)
+			if !ok {
+				return 0, fmt.Errorf("record %d: %w: %s", n, ErrInvalidField, path)
+			}
+			return v, nil
+		}
+		d := DataPoint{Variables: make([]float64, len(varFields))}
+		if d.Observed, err = field(observedField); err != nil {
+			return err
+		}
+		for i, path := range varFields {
+			if d.Variables[i], err = field(path); err != nil {
+				return err
+			}
+		}
+		data = append(data, d)
+	}
+
+	r.SetObserved(observedField)
+	for i, path := range varFields {
+		r.SetVar(i, path)
+	}
+	r.Train(data...)
+	return nil
+}
@@ -0,0 +1,49 @@
+package regression
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTrainJSONLines(t *testing.T) {
+	records := `{"event": "load", "latency": 12, "req": {"size": 1, "hops": 2}}
+{"event": "load", "latency": 17, "req": {"size": 2, "hops": 3}}
+{"event": "load", "latency": 18, "req": {"size": 3, "hops": 2}}
+
+{"event": "load", "latency": 25, "req": {"size": 4, "hops": 4}}
+{"event": "load", "latency": 22, "req": {"size": 5, "hops": 1}}
+`
+	r := &Regression{}
+	if err := r.TrainJSONLines(strings.NewReader(records), "latency", "req.size", "req.hops"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Data) != 5 || r.GetObserved() != "latency" || r.GetVar(1) != "req.hops" {
+		t.Errorf("Unexpected data points %v named %s, %s", r.Data, r.GetObserved(), r.GetVar(1))
+	}
+	// latency = 5 + 3*size + 2*hops
+	for i, expected := range []float64{5, 3, 2} {
+		if math.Abs(r.Coeff(i)-expected) > 1e-9 {
+			t.Errorf("Expected coefficient %d to be %v, got %v", i, expected, r.Coeff(i))
+		}
+	}
+
+	r = &Regression{}
+	err := r.TrainJSONLines(strings.NewReader(`{"y": 1, "x": 2}`+"\n"+`{"y": 1, "x": "2"}`), "y", "x")
+	if !errors.Is(err, ErrInvalidField) || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("Expected %v on record 2, got %v", ErrInvalidField, err)
+	}
+	if len(r.Data) != 0 {
+		t.Errorf("Expected no data point to be added, got %d", len(r.Data))
+	}
+	if err := r.TrainJSONLines(strings.NewReader(`{"y": 1, "x": {}}`), "y", "x.z.w"); !errors.Is(err, ErrInvalidField) {
+		t.Errorf("Expected %v, got %v", ErrInvalidField, err)
+	}
+	if err := r.TrainJSONLines(strings.NewReader(`{"y": 1,`), "y"); err == nil {
+		t.Error("Expected an error decoding a truncated record")
+	}
+}
@@ -0,0 +1,111 @@
+package regression
+
+import "math"
+
+// Multinomial is a multiclass logistic regression, whose observed values are class indices 0, 1, ...
+// It is fitted one-vs-rest: a logistic regression per class separates it from the other classes, and
+// the probabilities of the classes are the normalized probabilities of these regressions.
+type Multinomial struct {
+	Data    []DataPoint
+	crosses []featureCross
+	models  []*Regression
+}
+
+// Train adds training data points, whose Observed value is the index of their class.
+func (m *Multinomial) Train(d ...DataPoint) {
+	m.Data = append(m.Data, d...)
+}
+
+// AddCross registers a feature cross, shared by the regressions of all the classes.
+func (m *Multinomial) AddCross(cross featureCross) {
+	m.crosses = append(m.crosses, cross)
+}
+
+// Run fits the regression of each class. There must be at least two classes.
+func (m *Multinomial) Run() error {
+	if len(m.Data) < 3 {
+		return ErrNotEnoughData
+	}
+	classes := 0
+	for _, d := range m.Data {
+		if d.Observed < 0 || d.Observed != math.Trunc(d.Observed) {
+			return ErrObservedDomain
+		}
+		if k := int(d.Observed) + 1; k > classes {
+			classes = k
+		}
+	}
+	if classes < 2 {
+		return ErrZeroVariance
+	}
+
+	models := make([]*Regression, classes)
+	for k := range models {
+		r := &Regression{}
+		for _, cross := range m.crosses {
+			r.AddCross(cross)
+		}
+		for _, d := range m.Data {
+			var observed float64
+			if int(d.Observed) == k {
+				observed = 1
+			}
+			r.Train(DataPoint{Observed: observed, Variables: d.Variables, Weight: d.Weight})
+		}
+		r.SetLogistic()
+		if err := r.Run(); err != nil {
+			return err
+		}
+		models[k] = r
+	}
+	m.models = models
+	return nil
+}
+
+// Predict returns the probability of each class for the inputed features.
+func (m *Multinomial) Predict(vars []float64) ([]float64, error) {
+	if m.models == nil {
+		return nil, ErrRegressionRun
+	}
+	probabilities := make([]float64, len(m.models))
+	var total float64
+	for k, r := range m.models {
+		p, err := r.Predict(vars)
+		if err != nil {
+			return nil, err
+		}
+		probabilities[k] = p
+		total += p
+	}
+	for k := range probabilities {
+		probabilities[k] /= total
+	}
+	return probabilities, nil
+}
+
+// PredictClass returns the most probable class for the inputed features.
+func (m *Multinomial) PredictClass(vars []float64) (int, error) {
+	probabilities, err := m.Predict(vars)
+	if err != nil {
+		return 0, err
+	}
+	best := 0
+	for k, p := range probabilities {
+		if p > probabilities[best] {
+			best = k
+		}
+	}
+	return best, nil
+}
+
+// Coeffs returns the coefficients of the regression of each class, in the order of GetCoeffs.
+func (m *Multinomial) Coeffs() ([][]float64, error) {
+	if m.models == nil {
+		return nil, ErrRegressionRun
+	}
+	coeffs := make([][]float64, len(m.models))
+	for k, r := range m.models {
+		coeffs[k] = r.GetCoeffs()
+	}
+	return coeffs, nil
+}
@@ -0,0 +1,55 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMultinomial(t *testing.T) {
+	m := &Multinomial{}
+	if _, err := m.Predict([]float64{0, 0}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+
+	// three clusters of points, around (0, 0), (4, 0) and (0, 4)
+	rnd := rand.New(rand.NewSource(1))
+	centers := [][]float64{{0, 0}, {4, 0}, {0, 4}}
+	for i := 0; i < 600; i++ {
+		k := i % 3
+		m.Train(DataPoint{Observed: float64(k), Variables: []float64{centers[k][0] + rnd.NormFloat64(), centers[k][1] + rnd.NormFloat64()}})
+	}
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, center := range centers {
+		probabilities, err := m.Predict(center)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var total float64
+		for _, p := range probabilities {
+			total += p
+		}
+		if len(probabilities) != 3 || math.Abs(total-1) > 1e-12 {
+			t.Errorf("Expected 3 probabilities summing to 1, got %v", probabilities)
+		}
+		if class, _ := m.PredictClass(center); class != k || probabilities[k] < 0.8 {
+			t.Errorf("Expected class %d to be the most probable, got %v", k, probabilities)
+		}
+	}
+
+	coeffs, err := m.Coeffs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coeffs) != 3 || coeffs[1][1] <= 0 || coeffs[2][2] <= 0 {
+		t.Errorf("Expected class 1 to grow with x and class 2 with y, got %v", coeffs)
+	}
+
+	m.Data[0].Observed = 0.5
+	if err := m.Run(); err != ErrObservedDomain {
+		t.Errorf("Expected %v, got %v", ErrObservedDomain, err)
+	}
+}
@@ -0,0 +1,157 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Update adds a data point to a regression which has already run, and refreshes the fit in O(p²)
+// with a rank-one update of (XᵀX)⁻¹, instead of running the regression again.
+// It is only supported for unconstrained and unregularized least squares fits.
+func (r *Regression) Update(d DataPoint) error {
+	if err := r.checkOnline(); err != nil {
+		return err
+	}
+	if len(d.Variables) != r.numVars {
+		return ErrVariableCount
+	}
+	if r.transformed && d.Observed <= 0 {
+		return ErrNonPositiveObserved
+	}
+	if d.Weight < 0 {
+		return ErrNegativeWeight
+	}
+
+	d.Crosses = nil
+	for _, c := range r.crosses {
+		d.Crosses = append(d.Crosses, c.Calculate(d.Variables)...)
+	}
+	row := append(append([]float64{1}, d.Variables...), d.Crosses...)
+	// without weights, the fit so far used a weight of 1 for the data points
+	w := d.weight()
+	if r.weights == nil {
+		w /= r.Data[0].weight()
+	}
+	if err := r.rankOne(row, r.response(d.Observed), w, 1); err != nil {
+		return err
+	}
+
+	_, p := r.variables.Dims()
+	variables, observed := new(mat.Dense), new(mat.Dense)
+	variables.Stack(r.variables, mat.NewDense(1, p, row))
+	observed.Stack(r.observed, mat.NewDense(1, 1, []float64{r.response(d.Observed)}))
+	r.variables, r.observed = variables, observed
+	if r.weights != nil || w != 1 {
+		if r.weights == nil {
+			r.weights = make([]float64, len(r.Data))
+			for i := range r.weights {
+				r.weights[i] = 1
+			}
+		}
+		r.weights = append(r.weights, w)
+	}
+	r.Data = append(r.Data, d)
+	r.refresh()
+	return nil
+}
+
+// Downdate removes the data point at index from a regression which has already run, and refreshes
+// the fit with a rank-one downdate of (XᵀX)⁻¹. The coefficients are those a full run without the
+// data point would give, up to rounding errors.
+// It is only supported for unconstrained and unregularized least squares fits.
+func (r *Regression) Downdate(index int) error {
+	if err := r.checkOnline(); err != nil {
+		return err
+	}
+	n, p := r.variables.Dims()
+	if index < 0 || index >= n {
+		return ErrInvalidIndex
+	}
+	if n-1 < p {
+		return &InsufficientObservationsError{Have: n - 1, Need: p}
+	}
+
+	w := 1.0
+	if r.weights != nil {
+		w = r.weights[index]
+	}
+	if err := r.rankOne(mat.Row(nil, index, r.variables), r.observed.At(index, 0), w, -1); err != nil {
+		return err
+	}
+
+	variables := mat.NewDense(n-1, p, nil)
+	observed := mat.NewDense(n-1, 1, nil)
+	for i, k := 0, 0; i < n; i++ {
+		if i == index {
+			continue
+		}
+		variables.SetRow(k, r.variables.RawRowView(i))
+		observed.Set(k, 0, r.observed.At(i, 0))
+		k++
+	}
+	r.variables, r.observed = variables, observed
+	if r.weights != nil {
+		r.weights = append(r.weights[:index:index], r.weights[index+1:]...)
+	}
+	r.Data = append(r.Data[:index:index], r.Data[index+1:]...)
+	r.refresh()
+	return nil
+}
+
+// checkOnline verifies that the fit can be updated incrementally.
+func (r *Regression) checkOnline() error {
+	if err := r.requireData(); err != nil {
+		return err
+	}
+	if len(r.fixed) > 0 || !r.leastSquares() {
+		return ErrUnsupportedFit
+	}
+	// the design matrix must match the data points, which it doesn't when RunRANSAC left outliers out of
+	// the fit or data points were trained since the last run
+	if n, _ := r.variables.Dims(); n != len(r.Data) {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// rankOne adds (sign 1) or removes (sign -1) the weighted observation y of the design row to (XᵀX)⁻¹
+// and to the coefficients, using the Sherman-Morrison formula.
+func (r *Regression) rankOne(row []float64, y, w float64, sign float64) error {
+	inv, err := r.inverseCrossProduct()
+	if err != nil {
+		return err
+	}
+	p := len(row)
+	x := mat.NewVecDense(p, row)
+	x.ScaleVec(math.Sqrt(w), x)
+	y *= math.Sqrt(w)
+
+	k := mat.NewVecDense(p, nil)
+	k.MulVec(inv, x)
+	denom := 1 + sign*mat.Dot(x, k)
+	if math.Abs(denom) < 1e-12 {
+		return ErrSingularMatrix
+	}
+	updated := mat.NewSymDense(p, nil)
+	updated.SymRankOne(inv, -sign/denom, k)
+
+	var fitted float64
+	for j := 0; j < p; j++ {
+		fitted += r.coeff[j] * x.AtVec(j)
+	}
+	gain := mat.NewVecDense(p, nil)
+	gain.MulVec(updated, x)
+	for j := 0; j < p; j++ {
+		r.coeff[j] += sign * gain.AtVec(j) * (y - fitted)
+	}
+	r.xtxInv = updated
+	return nil
+}
+
+// refresh recomputes the statistics of the fit after the coefficients changed.
+func (r *Regression) refresh() {
+	r.calcPredicted()
+	r.calcVariance()
+	r.calcR2()
+}
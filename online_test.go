@@ -0,0 +1,81 @@
+package regression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdateDowndate(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		data = append(data, DataPoint{Observed: 1 + 2*x - y + math.Cos(7*x), Variables: []float64{x, y}})
+	}
+	extra := DataPoint{Observed: 30, Variables: []float64{4.5, 0.2}}
+
+	r := &Regression{}
+	if err := r.Update(extra); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.AddCross(MultiplierCross(0, 1))
+	r.Train(data...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	original := r.GetCoeffs()
+
+	if err := r.Update(extra); err != nil {
+		t.Fatal(err)
+	}
+	full := &Regression{}
+	full.AddCross(MultiplierCross(0, 1))
+	full.Train(data...)
+	full.Train(extra)
+	if err := full.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range full.GetCoeffs() {
+		if math.Abs(r.Coeff(i)-c) > 1e-9 {
+			t.Errorf("Expected updated coefficients %v, got %v", full.GetCoeffs(), r.GetCoeffs())
+		}
+	}
+	if math.Abs(r.R2-full.R2) > 1e-9 {
+		t.Errorf("Expected R^2 %v, got %v", full.R2, r.R2)
+	}
+
+	if err := r.Downdate(len(r.Data) - 1); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range original {
+		if math.Abs(r.Coeff(i)-c) > 1e-9 {
+			t.Errorf("Expected the original coefficients %v, got %v", original, r.GetCoeffs())
+		}
+	}
+	if len(r.Data) != len(data) {
+		t.Errorf("Expected %d data points, got %d", len(data), len(r.Data))
+	}
+
+	if err := r.Downdate(len(r.Data)); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+
+	// a data point trained since the last run is not in the fit
+	r.Train(extra)
+	if err := r.Update(extra); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestDowndateMinimum(t *testing.T) {
+	r := &Regression{}
+	r.Train(MakeDataPoints([][]float64{{1, 1}, {3, 2}, {2, 3}}, 0)...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Downdate(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Downdate(0); err == nil {
+		t.Error("Expected an error when removing below the required observations")
+	}
+}
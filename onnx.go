@@ -0,0 +1,278 @@
+package regression
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+)
+
+// protoMessage is a protocol buffers message being encoded, appended to field by field.
+type protoMessage []byte
+
+// Wire types of the protocol buffers encoding.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// appendVarint appends v in the base 128 varint encoding.
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func (m protoMessage) key(field, wireType int) protoMessage {
+	return appendVarint(m, uint64(field<<3|wireType))
+}
+
+func (m protoMessage) varint(field int, v int64) protoMessage {
+	return appendVarint(m.key(field, wireVarint), uint64(v))
+}
+
+func (m protoMessage) bytes(field int, b []byte) protoMessage {
+	m = appendVarint(m.key(field, wireBytes), uint64(len(b)))
+	return append(m, b...)
+}
+
+func (m protoMessage) string(field int, s string) protoMessage {
+	return m.bytes(field, []byte(s))
+}
+
+// floats appends packed 32-bit floats.
+func (m protoMessage) floats(field int, values []float64) protoMessage {
+	packed := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(packed[4*i:], math.Float32bits(float32(v)))
+	}
+	return m.bytes(field, packed)
+}
+
+// Field numbers and enumerations of the ONNX protocol buffers schema, onnx.proto.
+const (
+	onnxModelIRVersion    = 1
+	onnxModelProducerName = 2
+	onnxModelGraph        = 7
+	onnxModelOpsetImport  = 8
+
+	onnxOpsetDomain  = 1
+	onnxOpsetVersion = 2
+
+	onnxGraphNode        = 1
+	onnxGraphName        = 2
+	onnxGraphInitializer = 5
+	onnxGraphInput       = 11
+	onnxGraphOutput      = 12
+
+	onnxNodeInput     = 1
+	onnxNodeOutput    = 2
+	onnxNodeName      = 3
+	onnxNodeOpType    = 4
+	onnxNodeAttribute = 5
+	onnxNodeDomain    = 7
+
+	onnxAttributeName   = 1
+	onnxAttributeI      = 3
+	onnxAttributeFloats = 7
+	onnxAttributeType   = 20
+
+	onnxTypeInt    = 2 // AttributeType INT
+	onnxTypeFloats = 6 // AttributeType FLOATS
+
+	onnxTensorDims      = 1
+	onnxTensorDataType  = 2
+	onnxTensorFloatData = 4
+	onnxTensorInt64Data = 7
+	onnxTensorName      = 8
+
+	onnxValueInfoName      = 1
+	onnxValueInfoType      = 2
+	onnxTypeTensor         = 1
+	onnxTensorTypeElemType = 1
+	onnxTensorTypeShape    = 2
+	onnxShapeDim           = 1
+	onnxDimValue           = 1
+	onnxDimParam           = 2
+
+	onnxFloat = 1 // TensorProto.DataType FLOAT
+	onnxInt64 = 7 // TensorProto.DataType INT64
+
+	onnxIRVersion = 7
+	onnxOpset     = 13 // version of the default operator set
+	onnxMLOpset   = 1  // version of the ai.onnx.ml operator set
+	onnxMLDomain  = "ai.onnx.ml"
+)
+
+// onnxGraph builds the graph of an ONNX model.
+type onnxGraph struct {
+	graph   protoMessage
+	nodes   int
+	columns map[int]string // outputs extracting the columns of the input
+}
+
+// name returns a unique name for an intermediate value.
+func (g *onnxGraph) name(prefix string) string {
+	g.nodes++
+	return prefix + strconv.Itoa(g.nodes)
+}
+
+// node adds a node computing op of inputs in the given domain, with attributes, and returns the name
+// of its output.
+func (g *onnxGraph) node(op, domain string, attributes []protoMessage, inputs ...string) string {
+	return g.namedNode(g.name(op), op, domain, attributes, inputs...)
+}
+
+// namedNode adds a node like node, with the given output name.
+func (g *onnxGraph) namedNode(output, op, domain string, attributes []protoMessage, inputs ...string) string {
+	var n protoMessage
+	for _, input := range inputs {
+		n = n.string(onnxNodeInput, input)
+	}
+	n = n.string(onnxNodeOutput, output).string(onnxNodeName, output).string(onnxNodeOpType, op)
+	for _, a := range attributes {
+		n = n.bytes(onnxNodeAttribute, a)
+	}
+	if domain != "" {
+		n = n.string(onnxNodeDomain, domain)
+	}
+	g.graph = g.graph.bytes(onnxGraphNode, n)
+	return output
+}
+
+// intAttribute returns an attribute message holding an integer.
+func intAttribute(name string, v int64) protoMessage {
+	return protoMessage(nil).string(onnxAttributeName, name).varint(onnxAttributeI, v).varint(onnxAttributeType, onnxTypeInt)
+}
+
+// floatsAttribute returns an attribute message holding floats.
+func floatsAttribute(name string, values []float64) protoMessage {
+	return protoMessage(nil).string(onnxAttributeName, name).floats(onnxAttributeFloats, values).varint(onnxAttributeType, onnxTypeFloats)
+}
+
+// scalar adds a float scalar initializer and returns its name.
+func (g *onnxGraph) scalar(v float64) string {
+	name := g.name("Constant")
+	t := protoMessage(nil).varint(onnxTensorDataType, onnxFloat).floats(onnxTensorFloatData, []float64{v}).string(onnxTensorName, name)
+	g.graph = g.graph.bytes(onnxGraphInitializer, t)
+	return name
+}
+
+// column returns the name of a value holding column i of the input, as an N×1 tensor.
+func (g *onnxGraph) column(i int) string {
+	if name, ok := g.columns[i]; ok {
+		return name
+	}
+	index := g.name("Index")
+	t := protoMessage(nil).varint(onnxTensorDims, 1).varint(onnxTensorDataType, onnxInt64).
+		bytes(onnxTensorInt64Data, appendVarint(nil, uint64(i))).string(onnxTensorName, index)
+	g.graph = g.graph.bytes(onnxGraphInitializer, t)
+	g.columns[i] = g.node("Gather", "", []protoMessage{intAttribute("axis", 1)}, "X", index)
+	return g.columns[i]
+}
+
+// cross adds the nodes computing the outputs of the feature cross described by spec, and returns the
+// names of its outputs.
+func (g *onnxGraph) cross(spec CrossSpec) ([]string, error) {
+	switch spec.Kind {
+	case "Pow":
+		return []string{g.node("Pow", "", nil, g.column(spec.Vars[0]), g.scalar(spec.Power))}, nil
+	case "Multiplier":
+		if len(spec.Vars) == 0 {
+			return nil, ErrNotSerializable
+		}
+		product := g.column(spec.Vars[0])
+		for _, v := range spec.Vars[1:] {
+			product = g.node("Mul", "", nil, product, g.column(v))
+		}
+		return []string{product}, nil
+	case "Divide":
+		return []string{g.node("Div", "", nil, g.column(spec.Vars[0]), g.column(spec.Vars[1]))}, nil
+	case "Abs":
+		return []string{g.node("Abs", "", nil, g.column(spec.Vars[0]))}, nil
+	case "Identity":
+		return []string{g.column(spec.Vars[0])}, nil
+	case "Sigmoid":
+		return []string{g.node("Sigmoid", "", nil, g.column(spec.Vars[0]))}, nil
+	case "Compose":
+		var outputs []string
+		for _, inner := range spec.Crosses {
+			names, err := g.cross(inner)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, names...)
+		}
+		return outputs, nil
+	default:
+		return nil, ErrNotSerializable
+	}
+}
+
+// valueInfo returns the description of a float tensor of N rows and the given number of columns.
+func valueInfo(name string, columns int) protoMessage {
+	shape := protoMessage(nil).
+		bytes(onnxShapeDim, protoMessage(nil).string(onnxDimParam, "N")).
+		bytes(onnxShapeDim, protoMessage(nil).varint(onnxDimValue, int64(columns)))
+	tensor := protoMessage(nil).varint(onnxTensorTypeElemType, onnxFloat).bytes(onnxTensorTypeShape, shape)
+	return protoMessage(nil).string(onnxValueInfoName, name).bytes(onnxValueInfoType, protoMessage(nil).bytes(onnxTypeTensor, tensor))
+}
+
+// ExportONNX writes the regression, which must have run, as an ONNX model, so that it can be scored by
+// ONNX runtimes. The model takes an N×p float tensor X of variables and returns an N×1 float tensor Y
+// of predictions, computed by a LinearRegressor node of the ai.onnx.ml domain. The feature crosses are
+// computed by standard operators, as well as the inverse of the response transformation or of the link
+// of the family. Only the built-in feature crosses and families and the log transformation of the
+// response can be exported, others yield ErrNotSerializable. The computations are in single precision.
+func (r *Regression) ExportONNX(w io.Writer) error {
+	m, err := r.toModel()
+	if err != nil {
+		return err
+	}
+	inverse := ""
+	switch {
+	case m.Family == Binomial.Name:
+		inverse = "Sigmoid"
+	case m.Family == Poisson.Name || m.Family == Gamma.Name:
+		inverse = "Exp"
+	case m.Transformed && m.Lambda == 0:
+		inverse = "Exp"
+	case m.Transformed:
+		return ErrNotSerializable
+	}
+
+	g := &onnxGraph{columns: map[int]string{}}
+	features := []string{"X"}
+	for _, spec := range m.Crosses {
+		outputs, err := g.cross(spec)
+		if err != nil {
+			return err
+		}
+		features = append(features, outputs...)
+	}
+	input := "X"
+	if len(features) > 1 {
+		input = g.node("Concat", "", []protoMessage{intAttribute("axis", 1)}, features...)
+	}
+	attributes := []protoMessage{
+		floatsAttribute("coefficients", m.Coeffs[1:]),
+		floatsAttribute("intercepts", m.Coeffs[:1]),
+	}
+	if inverse == "" {
+		g.namedNode("Y", "LinearRegressor", onnxMLDomain, attributes, input)
+	} else {
+		linear := g.node("LinearRegressor", onnxMLDomain, attributes, input)
+		g.namedNode("Y", inverse, "", nil, linear)
+	}
+
+	graph := g.graph.string(onnxGraphName, "regression").
+		bytes(onnxGraphInput, valueInfo("X", m.NumVars)).
+		bytes(onnxGraphOutput, valueInfo("Y", 1))
+	model := protoMessage(nil).
+		varint(onnxModelIRVersion, onnxIRVersion).
+		string(onnxModelProducerName, "github.com/cocoonspace/regression").
+		bytes(onnxModelGraph, graph).
+		bytes(onnxModelOpsetImport, protoMessage(nil).string(onnxOpsetDomain, "").varint(onnxOpsetVersion, onnxOpset)).
+		bytes(onnxModelOpsetImport, protoMessage(nil).string(onnxOpsetDomain, onnxMLDomain).varint(onnxOpsetVersion, onnxMLOpset))
+	_, err = w.Write(model)
+	return err
+}
@@ -0,0 +1,172 @@
+package regression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// protoField is a decoded field of a protocol buffers message.
+type protoField struct {
+	num    int
+	varint uint64
+	data   []byte
+}
+
+// decodeProto decodes the fields of a message, which must only use the varint and bytes wire types.
+func decodeProto(t *testing.T, b []byte) []protoField {
+	var fields []protoField
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		v, n := binary.Uvarint(b)
+		b = b[n:]
+		f := protoField{num: int(key >> 3), varint: v}
+		switch key & 7 {
+		case wireVarint:
+		case wireBytes:
+			f.data, b = b[:v], b[v:]
+		default:
+			t.Fatalf("Unexpected wire type %d", key&7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// get returns the fields numbered num.
+func get(fields []protoField, num int) []protoField {
+	var found []protoField
+	for _, f := range fields {
+		if f.num == num {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+func decodeFloats(b []byte) []float64 {
+	values := make([]float64, len(b)/4)
+	for i := range values {
+		values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:])))
+	}
+	return values
+}
+
+// scoreONNX evaluates the subset of ONNX written by ExportONNX on a single row of variables.
+func scoreONNX(t *testing.T, model []byte, vars []float64) float64 {
+	graph := decodeProto(t, get(decodeProto(t, model), onnxModelGraph)[0].data)
+	values := map[string][]float64{"X": vars}
+	for _, init := range get(graph, onnxGraphInitializer) {
+		tensor := decodeProto(t, init.data)
+		name := string(get(tensor, onnxTensorName)[0].data)
+		if data := get(tensor, onnxTensorFloatData); len(data) > 0 {
+			values[name] = decodeFloats(data[0].data)
+		} else {
+			index, _ := binary.Uvarint(get(tensor, onnxTensorInt64Data)[0].data)
+			values[name] = []float64{float64(index)}
+		}
+	}
+	for _, nodeField := range get(graph, onnxGraphNode) {
+		n := decodeProto(t, nodeField.data)
+		var args [][]float64
+		for _, input := range get(n, onnxNodeInput) {
+			args = append(args, values[string(input.data)])
+		}
+		unary := func(fn func(float64) float64) []float64 { return []float64{fn(args[0][0])} }
+		var out []float64
+		switch op := string(get(n, onnxNodeOpType)[0].data); op {
+		case "Gather":
+			out = []float64{args[0][int(args[1][0])]}
+		case "Pow":
+			out = []float64{math.Pow(args[0][0], args[1][0])}
+		case "Mul":
+			out = []float64{args[0][0] * args[1][0]}
+		case "Div":
+			out = []float64{args[0][0] / args[1][0]}
+		case "Abs":
+			out = unary(math.Abs)
+		case "Exp":
+			out = unary(math.Exp)
+		case "Sigmoid":
+			out = unary(func(x float64) float64 { return 1 / (1 + math.Exp(-x)) })
+		case "Concat":
+			for _, arg := range args {
+				out = append(out, arg...)
+			}
+		case "LinearRegressor":
+			var coeffs, intercept []float64
+			for _, a := range get(n, onnxNodeAttribute) {
+				attr := decodeProto(t, a.data)
+				switch string(get(attr, onnxAttributeName)[0].data) {
+				case "coefficients":
+					coeffs = decodeFloats(get(attr, onnxAttributeFloats)[0].data)
+				case "intercepts":
+					intercept = decodeFloats(get(attr, onnxAttributeFloats)[0].data)
+				}
+			}
+			if len(coeffs) != len(args[0]) {
+				t.Fatalf("Expected %d coefficients, got %d", len(args[0]), len(coeffs))
+			}
+			y := intercept[0]
+			for j, c := range coeffs {
+				y += c * args[0][j]
+			}
+			out = []float64{y}
+		default:
+			t.Fatalf("Unexpected operator %s", op)
+		}
+		values[string(get(n, onnxNodeOutput)[0].data)] = out
+	}
+	if _, ok := values["Y"]; !ok {
+		t.Fatal("Expected an output Y")
+	}
+	return values["Y"][0]
+}
+
+func TestExportONNX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Regression{}).ExportONNX(&buf); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r := persistedRegression(t)
+	r2 := &Regression{}
+	for _, d := range r.Data {
+		r2.Train(DataPoint{Observed: d.Observed, Variables: d.Variables})
+	}
+	// an identity cross would be collinear with its variable, which single precision coefficients do not bear
+	r2.AddCross(ComposeCrosses(SigmoidCross(1), DivideCross(1, 0)))
+	if err := r2.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, model := range []*Regression{r, r2} {
+		buf.Reset()
+		if err := model.ExportONNX(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var opsets []string
+		for _, opset := range get(decodeProto(t, buf.Bytes()), onnxModelOpsetImport) {
+			opsets = append(opsets, string(get(decodeProto(t, opset.data), onnxOpsetDomain)[0].data))
+		}
+		if len(opsets) != 2 || opsets[1] != onnxMLDomain {
+			t.Errorf("Expected the default and %s operator sets, got %q", onnxMLDomain, opsets)
+		}
+		for _, vars := range [][]float64{{3, 0.5}, {12, -0.8}} {
+			expected, _ := model.Predict(vars)
+			// the model computes in single precision
+			if scored := scoreONNX(t, buf.Bytes(), vars); math.Abs(scored-expected) > 1e-5*math.Abs(expected) {
+				t.Errorf("Expected ONNX score %v for %v, got %v", expected, vars, scored)
+			}
+		}
+	}
+
+	r.TransformResponse(0.5)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ExportONNX(&buf); err != ErrNotSerializable {
+		t.Errorf("Expected %v, got %v", ErrNotSerializable, err)
+	}
+}
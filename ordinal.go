@@ -0,0 +1,235 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Ordinal is an ordinal logistic regression, for ordered categories such as survey scores, whose
+// observed values are the category indices 0, 1, ... It fits the proportional odds model
+// P(y ≤ k) = 1/(1+exp(-(θₖ - Xc))), where the thresholds θₖ increase with k while the coefficients c,
+// which don't include an offset, are shared by all the categories.
+type Ordinal struct {
+	Data       []DataPoint
+	base       Regression // holds the feature crosses
+	numVars    int
+	coeffs     []float64
+	thresholds []float64
+}
+
+// Train adds training data points, whose Observed value is the index of their category.
+func (o *Ordinal) Train(d ...DataPoint) {
+	o.Data = append(o.Data, d...)
+}
+
+// AddCross registers a feature cross to be applied to the data points.
+func (o *Ordinal) AddCross(cross featureCross) {
+	o.base.AddCross(cross)
+}
+
+// Run fits the thresholds and the coefficients by maximum likelihood, with Newton's method.
+// Every category up to the largest observed one must have training data points.
+func (o *Ordinal) Run() error {
+	if len(o.Data) < 3 {
+		return ErrNotEnoughData
+	}
+	if err := DataPoints(o.Data).Validate(); err != nil {
+		return err
+	}
+	categories := 0
+	for _, d := range o.Data {
+		if d.Observed < 0 || d.Observed != math.Trunc(d.Observed) {
+			return ErrObservedDomain
+		}
+		if k := int(d.Observed) + 1; k > categories {
+			categories = k
+		}
+	}
+	if categories < 2 {
+		return ErrZeroVariance
+	}
+	counts := make([]float64, categories)
+	features := make([][]float64, len(o.Data))
+	var total float64
+	for i, d := range o.Data {
+		counts[int(d.Observed)] += d.weight()
+		total += d.weight()
+		features[i] = o.base.features(d.Variables)
+	}
+	for _, count := range counts {
+		if count == 0 {
+			return ErrNotEnoughData
+		}
+	}
+	p := len(features[0])
+	if len(o.Data) < categories-1+p {
+		return &InsufficientObservationsError{Have: len(o.Data), Need: categories - 1 + p}
+	}
+
+	// start from the thresholds of the observed cumulative proportions, without effect of the variables
+	params := make([]float64, categories-1+p)
+	var cumulative float64
+	for k := 0; k < categories-1; k++ {
+		cumulative += counts[k] / total
+		params[k] = math.Log(cumulative / (1 - cumulative))
+	}
+
+	loglik, gradient, hessian := o.likelihood(features, params, categories)
+	for iter := 0; iter < maxIRLSIterations; iter++ {
+		// the Newton step solves -H step = g, -H being positive definite at the solution
+		neg := mat.NewSymDense(len(params), nil)
+		neg.ScaleSym(-1, hessian)
+		var chol mat.Cholesky
+		if !chol.Factorize(neg) {
+			return ErrSingularMatrix
+		}
+		step := mat.NewVecDense(len(params), nil)
+		if err := chol.SolveVecTo(step, mat.NewVecDense(len(params), gradient)); err != nil {
+			return ErrSingularMatrix
+		}
+
+		// halve the step until it keeps the thresholds ordered and increases the likelihood
+		var change float64
+		for scale := 1.0; scale > 1e-10; scale /= 2 {
+			candidate := make([]float64, len(params))
+			for j := range candidate {
+				candidate[j] = params[j] + scale*step.AtVec(j)
+			}
+			if !ordered(candidate[:categories-1]) {
+				continue
+			}
+			l, g, h := o.likelihood(features, candidate, categories)
+			if l >= loglik {
+				change = scale * mat.Norm(step, math.Inf(1))
+				params, loglik, gradient, hessian = candidate, l, g, h
+				break
+			}
+		}
+		if change < irlsTolerance {
+			break
+		}
+	}
+
+	o.numVars = len(o.Data[0].Variables)
+	o.thresholds = params[:categories-1]
+	o.coeffs = params[categories-1:]
+	return nil
+}
+
+// likelihood returns the log-likelihood of the proportional odds model with the given parameters, the
+// thresholds followed by the coefficients, along with its gradient and Hessian.
+func (o *Ordinal) likelihood(features [][]float64, params []float64, categories int) (float64, []float64, *mat.SymDense) {
+	q := len(params)
+	thresholds, coeffs := params[:categories-1], params[categories-1:]
+	var loglik float64
+	gradient := make([]float64, q)
+	hessian := mat.NewSymDense(q, nil)
+	dp := make([]float64, q)
+	d2p := mat.NewSymDense(q, nil)
+
+	for i, d := range o.Data {
+		var eta float64
+		for j, val := range features[i] {
+			eta += coeffs[j] * val
+		}
+		k := int(d.Observed)
+		for j := range dp {
+			dp[j] = 0
+		}
+		d2p.Zero()
+
+		// P(y = k) = F(θₖ - η) - F(θₖ₋₁ - η), with F(θ₋₁) = 0 and F(θ_last) = 1
+		var prob float64
+		for _, bound := range []struct {
+			index int
+			sign  float64
+		}{{k, 1}, {k - 1, -1}} {
+			if bound.index < 0 || bound.index >= categories-1 {
+				if bound.sign > 0 {
+					prob++
+				}
+				continue
+			}
+			cdf := 1 / (1 + math.Exp(-(thresholds[bound.index] - eta)))
+			pdf := cdf * (1 - cdf)
+			dpdf := pdf * (1 - 2*cdf)
+			prob += bound.sign * cdf
+
+			dp[bound.index] += bound.sign * pdf
+			d2p.SetSym(bound.index, bound.index, d2p.At(bound.index, bound.index)+bound.sign*dpdf)
+			for j, val := range features[i] {
+				b := categories - 1 + j
+				dp[b] -= bound.sign * pdf * val
+				d2p.SetSym(bound.index, b, d2p.At(bound.index, b)-bound.sign*dpdf*val)
+				for l := j; l < len(features[i]); l++ {
+					c := categories - 1 + l
+					d2p.SetSym(b, c, d2p.At(b, c)+bound.sign*dpdf*val*features[i][l])
+				}
+			}
+		}
+		prob = math.Max(prob, 1e-300)
+
+		// the derivatives of log P are ∇P/P and ∇²P/P - ∇P∇Pᵀ/P²
+		w := d.weight()
+		loglik += w * math.Log(prob)
+		for a := 0; a < q; a++ {
+			gradient[a] += w * dp[a] / prob
+			for b := a; b < q; b++ {
+				hessian.SetSym(a, b, hessian.At(a, b)+w*(d2p.At(a, b)/prob-dp[a]*dp[b]/(prob*prob)))
+			}
+		}
+	}
+	return loglik, gradient, hessian
+}
+
+// ordered reports whether the thresholds are strictly increasing.
+func ordered(thresholds []float64) bool {
+	for k := 1; k < len(thresholds); k++ {
+		if thresholds[k] <= thresholds[k-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// Predict returns the probability of each category for the inputed features.
+func (o *Ordinal) Predict(vars []float64) ([]float64, error) {
+	if o.coeffs == nil {
+		return nil, ErrRegressionRun
+	}
+	if len(vars) != o.numVars {
+		return nil, ErrVariableCount
+	}
+	var eta float64
+	for j, val := range o.base.features(vars) {
+		eta += o.coeffs[j] * val
+	}
+	probabilities := make([]float64, len(o.thresholds)+1)
+	var previous float64
+	for k, threshold := range o.thresholds {
+		cdf := 1 / (1 + math.Exp(-(threshold - eta)))
+		probabilities[k] = cdf - previous
+		previous = cdf
+	}
+	probabilities[len(o.thresholds)] = 1 - previous
+	return probabilities, nil
+}
+
+// Coeffs returns the coefficients of the variables followed by those of the feature crosses. A
+// positive coefficient means that the variable makes the higher categories more likely.
+func (o *Ordinal) Coeffs() ([]float64, error) {
+	if o.coeffs == nil {
+		return nil, ErrRegressionRun
+	}
+	return append([]float64(nil), o.coeffs...), nil
+}
+
+// Thresholds returns the increasing cut-points θₖ on the scale of the linear predictor, between the
+// category k and the category k+1.
+func (o *Ordinal) Thresholds() ([]float64, error) {
+	if o.thresholds == nil {
+		return nil, ErrRegressionRun
+	}
+	return append([]float64(nil), o.thresholds...), nil
+}
@@ -0,0 +1,66 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestOrdinal(t *testing.T) {
+	o := &Ordinal{}
+	if _, err := o.Predict([]float64{0}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+
+	// survey scores from 0 to 4, drawn from a latent logistic variable
+	rnd := rand.New(rand.NewSource(1))
+	thresholds := []float64{-2, -0.5, 1, 2.5}
+	for i := 0; i < 5000; i++ {
+		x, y := rnd.NormFloat64(), rnd.NormFloat64()
+		u := rnd.Float64()
+		latent := 1.5*x - 0.5*y + math.Log(u/(1-u))
+		score := 0
+		for score < len(thresholds) && latent > thresholds[score] {
+			score++
+		}
+		o.Train(DataPoint{Observed: float64(score), Variables: []float64{x, y}})
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	coeffs, err := o.Coeffs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(coeffs[0]-1.5) > 0.1 || math.Abs(coeffs[1]+0.5) > 0.1 {
+		t.Errorf("Expected coefficients close to [1.5 -0.5], got %v", coeffs)
+	}
+	fitted, err := o.Thresholds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range thresholds {
+		if math.Abs(fitted[k]-thresholds[k]) > 0.15 {
+			t.Errorf("Expected thresholds close to %v, got %v", thresholds, fitted)
+			break
+		}
+	}
+
+	probabilities, err := o.Predict([]float64{2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, p := range probabilities {
+		total += p
+	}
+	if len(probabilities) != 5 || math.Abs(total-1) > 1e-12 || probabilities[4] < probabilities[0] {
+		t.Errorf("Expected 5 probabilities summing to 1 favouring high scores, got %v", probabilities)
+	}
+
+	o.Data[0].Observed = 7
+	if err := o.Run(); err != ErrNotEnoughData {
+		t.Errorf("Expected %v for missing categories, got %v", ErrNotEnoughData, err)
+	}
+}
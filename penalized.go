@@ -0,0 +1,205 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Regularization is a penalty on the size of the coefficients, which shrinks them to trade a little
+// bias for a lower variance, e.g. when variables are correlated.
+type Regularization int
+
+const (
+	// NoRegularization fits ordinary least squares.
+	NoRegularization Regularization = iota
+	// L2 penalizes the sum of the squared coefficients, as in ridge regression.
+	L2
+	// L1 penalizes the sum of the absolute values of the coefficients, as in lasso regression, which
+	// sets the coefficients of the least relevant variables to exactly zero.
+	L1
+	// ElasticNet penalizes a mix of both, see SetElasticNet.
+	ElasticNet
+)
+
+// Settings of the coordinate descent used for penalties with an L1 component.
+const (
+	maxDescentSweeps = 10000
+	descentTolerance = 1e-12
+)
+
+// SetRegularization makes Run minimise the sum of the squared residuals plus lambda times the penalty
+// of the coefficients, the offset being left unpenalized. As the penalty depends on the scale of the
+// variables, they are best standardized first, see Scaler. Regularization can't be combined with
+// fixed or non-negative coefficients, nor with Accumulate.
+func (r *Regression) SetRegularization(penalty Regularization, lambda float64) {
+	r.penalty = penalty
+	r.penaltyLambda = lambda
+}
+
+// SetElasticNet makes Run minimise the sum of the squared residuals plus
+// alpha (l1Ratio Σ|cᵢ| + (1-l1Ratio) Σcᵢ²), which behaves as lasso for a ratio of 1 and as ridge for
+// a ratio of 0. Unlike lasso, it tends to keep groups of correlated variables together.
+func (r *Regression) SetElasticNet(alpha, l1Ratio float64) {
+	r.SetRegularization(ElasticNet, alpha)
+	r.l1Ratio = l1Ratio
+}
+
+// penalties splits a penalty of lambda into its L1 and L2 parts, the L2 one being used when there is
+// no regularization.
+func (r *Regression) penalties(lambda float64) (l1, l2 float64) {
+	switch r.penalty {
+	case L1:
+		return lambda, 0
+	case ElasticNet:
+		return lambda * r.l1Ratio, lambda * (1 - r.l1Ratio)
+	}
+	return 0, lambda
+}
+
+// ZeroCoeffs returns the indices, in the order of GetCoeffs, of the coefficients which the L1 component
+// of the regularization set to zero, that is of the variables and feature crosses it deselected.
+func (r *Regression) ZeroCoeffs() ([]int, error) {
+	if !r.Ready {
+		return nil, ErrRegressionRun
+	}
+	var zeroed []int
+	if l1, _ := r.penalties(r.penaltyLambda); l1 == 0 {
+		return zeroed, nil
+	}
+	for i := 1; i < len(r.coeff); i++ {
+		if r.coeff[i] == 0 {
+			zeroed = append(zeroed, i)
+		}
+	}
+	return zeroed, nil
+}
+
+// checkPenalty validates the regularization settings.
+func (r *Regression) checkPenalty() error {
+	if r.penalty == NoRegularization {
+		return nil
+	}
+	if r.penaltyLambda < 0 {
+		return ErrNegativePenalty
+	}
+	if r.penalty == ElasticNet && (r.l1Ratio < 0 || r.l1Ratio > 1) {
+		return ErrInvalidRatio
+	}
+	if len(r.fixed) > 0 || r.nonNegative {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// RegPath returns the coefficients of penalized regressions fitted on the data of the last run, feature
+// crosses included, for each of the given penalties. This allows to plot how the coefficients shrink
+// as the penalty grows. The kind of penalty set by SetRegularization or SetElasticNet is used, L2
+// being the default.
+func (r *Regression) RegPath(lambdas []float64) ([][]float64, error) {
+	if err := r.requireData(); err != nil {
+		return nil, err
+	}
+	// the whitened design of a generalized least squares fit has no column of ones to center on
+	if r.errorCov != nil {
+		return nil, ErrUnsupportedFit
+	}
+	path := make([][]float64, len(lambdas))
+	for i, lambda := range lambdas {
+		if lambda < 0 {
+			return nil, ErrNegativePenalty
+		}
+		path[i] = r.solvePenalized(r.variables, r.observed, r.weights, lambda)
+	}
+	return path, nil
+}
+
+// solvePenalized returns the coefficients of the regularized fit with a penalty of lambda.
+func (r *Regression) solvePenalized(variables, observed *mat.Dense, weights []float64, lambda float64) []float64 {
+	l1, l2 := r.penalties(lambda)
+	if l1 == 0 {
+		return solveRidge(variables, observed, weights, l2)
+	}
+	return solveElasticNet(variables, observed, weights, l1, l2)
+}
+
+// solveRidge returns the coefficients minimising the sum of the squared residuals plus lambda times
+// the sum of the squared coefficients, the offset being left unpenalized. The first column of
+// variables must be the column of ones.
+func solveRidge(variables, observed *mat.Dense, weights []float64, lambda float64) []float64 {
+	centered, centeredObserved, means, obmean := center(variables, observed, weights)
+	if weights != nil {
+		centered, centeredObserved = weightRows(centered, weights), weightRows(centeredObserved, weights)
+	}
+
+	// the penalty amounts to extra observations of sqrt(lambda) times each variable being 0
+	n, p := centered.Dims()
+	augmented := mat.NewDense(n+p, p, nil)
+	augmented.Slice(0, n, 0, p).(*mat.Dense).Copy(centered)
+	for j := 0; j < p; j++ {
+		augmented.Set(n+j, j, math.Sqrt(lambda))
+	}
+	augmentedObserved := mat.NewDense(n+p, 1, nil)
+	augmentedObserved.Slice(0, n, 0, 1).(*mat.Dense).Copy(centeredObserved)
+
+	return withOffset(solveLeastSquares(augmented, augmentedObserved), means, obmean)
+}
+
+// solveElasticNet returns the coefficients minimising the sum of the squared residuals plus l1 times
+// the sum of the absolute values of the coefficients and l2 times the sum of their squares, the offset
+// being left unpenalized. It uses cyclic coordinate descent, each coefficient being in turn set to its
+// soft-thresholded and shrunk least squares value given the others. The first column of variables must
+// be the column of ones.
+func solveElasticNet(variables, observed *mat.Dense, weights []float64, l1, l2 float64) []float64 {
+	centered, centeredObserved, means, obmean := center(variables, observed, weights)
+	if weights != nil {
+		centered, centeredObserved = weightRows(centered, weights), weightRows(centeredObserved, weights)
+	}
+
+	n, p := centered.Dims()
+	c := make([]float64, p)
+	residuals := mat.Col(nil, 0, centeredObserved)
+	norms := make([]float64, p)
+	for j := range norms {
+		for i := 0; i < n; i++ {
+			norms[j] += centered.At(i, j) * centered.At(i, j)
+		}
+	}
+
+	for sweep := 0; sweep < maxDescentSweeps; sweep++ {
+		var change float64
+		for j := 0; j < p; j++ {
+			if norms[j] == 0 {
+				continue
+			}
+			// correlation of the variable with the residuals excluding its own contribution
+			rho := norms[j] * c[j]
+			for i := 0; i < n; i++ {
+				rho += centered.At(i, j) * residuals[i]
+			}
+			updated := softThreshold(rho, l1/2) / (norms[j] + l2)
+			if delta := updated - c[j]; delta != 0 {
+				for i := 0; i < n; i++ {
+					residuals[i] -= delta * centered.At(i, j)
+				}
+				change = math.Max(change, delta*delta*(norms[j]+l2))
+				c[j] = updated
+			}
+		}
+		if change <= descentTolerance {
+			break
+		}
+	}
+	return withOffset(c, means, obmean)
+}
+
+// softThreshold shrinks x towards zero by t, returning zero when |x| <= t.
+func softThreshold(x, t float64) float64 {
+	switch {
+	case x > t:
+		return x - t
+	case x < -t:
+		return x + t
+	}
+	return 0
+}
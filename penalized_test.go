@@ -0,0 +1,189 @@
+package regression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegPath(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.RegPath([]float64{1}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 30; i++ {
+		x, y := float64(i)/10, math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + 2*x - 3*y + 0.5*x*x + 0.3*math.Cos(5*x), Variables: []float64{x, y}})
+	}
+	r.AddCross(PowCross(0, 2))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := r.RegPath([]float64{0, 0.1, 1, 10, 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(path[0][i]-c) > 1e-9 {
+			t.Errorf("Expected no penalty to match the least squares coefficients %v, got %v", r.GetCoeffs(), path[0])
+		}
+	}
+	norm := func(c []float64) float64 {
+		var sum float64
+		for _, val := range c[1:] {
+			sum += val * val
+		}
+		return sum
+	}
+	for i := 1; i < len(path); i++ {
+		if norm(path[i]) >= norm(path[i-1]) {
+			t.Errorf("Expected coefficients to shrink as the penalty grows, got %v then %v", path[i-1], path[i])
+		}
+	}
+
+	if _, err := r.RegPath([]float64{-1}); err != ErrNegativePenalty {
+		t.Errorf("Expected %v, got %v", ErrNegativePenalty, err)
+	}
+}
+
+func TestSetRegularization(t *testing.T) {
+	// two nearly collinear variables, on which least squares coefficients blow up
+	train := func(r *Regression) {
+		for i := 0; i < 30; i++ {
+			x := float64(i) / 10
+			r.Train(DataPoint{Observed: 1 + 2*x + 0.1*math.Sin(float64(i)), Variables: []float64{x, x + 1e-3*math.Cos(float64(i))}})
+		}
+	}
+	ols := &Regression{}
+	train(ols)
+	if err := ols.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	ridge := &Regression{}
+	train(ridge)
+	ridge.SetRegularization(L2, 0.1)
+	if err := ridge.Run(); err != nil {
+		t.Fatal(err)
+	}
+	path, err := ols.RegPath([]float64{0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ridge.GetCoeffs()
+	for i := range c {
+		if math.Abs(path[0][i]-c[i]) > 1e-9 {
+			t.Errorf("Expected the coefficients of the ridge path %v, got %v", path[0], c)
+			break
+		}
+	}
+	if o := ols.GetCoeffs(); math.Abs(c[1]-c[2]) > 0.1 || math.Abs(c[1]-c[2]) > math.Abs(o[1]-o[2]) {
+		t.Errorf("Expected the coefficients to shrink towards each other, got %v, least squares %v", c, ols.GetCoeffs())
+	}
+
+	ridge.SetRegularization(L2, -1)
+	if err := ridge.Run(); err != ErrNegativePenalty {
+		t.Errorf("Expected %v, got %v", ErrNegativePenalty, err)
+	}
+	ridge.SetRegularization(L2, 1)
+	ridge.SetNonNegative(true)
+	if err := ridge.Run(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestLasso(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 40; i++ {
+		x, y, z := float64(i%7), math.Sin(float64(i)), math.Cos(float64(3*i))
+		r.Train(DataPoint{Observed: 1 + 2*x - 3*y + 0.01*math.Sin(float64(5*i)), Variables: []float64{x, y, z}})
+	}
+	r.SetRegularization(L1, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	path, err := r.RegPath([]float64{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ols := solveLeastSquares(r.variables, r.observed)
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(c-ols[i]) > 1e-6 || math.Abs(path[0][i]-ols[i]) > 1e-6 {
+			t.Errorf("Expected no penalty to match the least squares coefficients %v, got %v", ols, r.GetCoeffs())
+			break
+		}
+	}
+
+	r.SetRegularization(L1, 5)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := r.GetCoeffs()
+	if c[3] != 0 {
+		t.Errorf("Expected the irrelevant variable to be zeroed, got %v", c)
+	}
+	if math.Abs(c[1]-2) > 0.1 || math.Abs(c[2]+3) > 0.3 {
+		t.Errorf("Expected the relevant variables to be kept, got %v", c)
+	}
+
+	path, err = r.RegPath([]float64{1e4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, val := range path[0][1:] {
+		if val != 0 {
+			t.Errorf("Expected a large penalty to zero all coefficients, got %v", path[0])
+			break
+		}
+	}
+}
+
+func TestElasticNet(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.ZeroCoeffs(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 40; i++ {
+		x, y, z := float64(i%7), math.Sin(float64(i)), math.Cos(float64(3*i))
+		r.Train(DataPoint{Observed: 1 + 2*x - 3*y + 0.01*math.Sin(float64(5*i)), Variables: []float64{x, y, z}})
+	}
+
+	// the extreme ratios match ridge and lasso
+	for _, test := range []struct {
+		ratio   float64
+		penalty Regularization
+	}{{0, L2}, {1, L1}} {
+		r.SetRegularization(test.penalty, 3)
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		expected := r.GetCoeffs()
+		r.SetElasticNet(3, test.ratio)
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		for i, c := range r.GetCoeffs() {
+			if math.Abs(c-expected[i]) > 1e-6 {
+				t.Errorf("Expected a ratio of %v to give %v, got %v", test.ratio, expected, r.GetCoeffs())
+				break
+			}
+		}
+	}
+
+	r.SetElasticNet(5, 0.8)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	zeroed, err := r.ZeroCoeffs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zeroed) != 1 || zeroed[0] != 3 {
+		t.Errorf("Expected the coefficient of the irrelevant variable to be zeroed, got %v for %v", zeroed, r.GetCoeffs())
+	}
+
+	r.SetElasticNet(5, 1.5)
+	if err := r.Run(); err != ErrInvalidRatio {
+		t.Errorf("Expected %v, got %v", ErrInvalidRatio, err)
+	}
+}
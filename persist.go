@@ -0,0 +1,187 @@
+package regression
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+)
+
+// Identification of the binary format written by Save.
+const (
+	modelFormat  = "regression"
+	modelVersion = 1
+)
+
+// modelHeader precedes the model in the binary format.
+type modelHeader struct {
+	Format  string
+	Version int
+}
+
+// model is the serialized form of a regression which has run: what Predict needs, the names of the
+// variables and the fit statistics, which are null in JSON when they are not finite, e.g. the R2 of a
+// constant response. The training data points are not retained.
+type model struct {
+	Observed          string         `json:"observed,omitempty"`
+	VarNames          map[int]string `json:"var_names,omitempty"`
+	NumVars           int            `json:"num_vars"`
+	Crosses           []CrossSpec    `json:"crosses,omitempty"`
+	Transformed       bool           `json:"transformed,omitempty"`
+	Lambda            float64        `json:"lambda,omitempty"`
+	Family            string         `json:"family,omitempty"`
+	Coeffs            []float64      `json:"coeffs"`
+	R2                jsonFloat      `json:"r2"`
+	AdjustedR2        jsonFloat      `json:"adjusted_r2"`
+	WeightedR2        jsonFloat      `json:"weighted_r2"`
+	VarianceObserved  jsonFloat      `json:"variance_observed"`
+	VariancePredicted jsonFloat      `json:"variance_predicted"`
+}
+
+// families are the built-in families, which can be serialized by name.
+var families = []*Family{Gaussian, Poisson, Binomial, Gamma}
+
+// toModel returns the serialized form of the regression, which must have run with built-in feature
+// crosses and family.
+func (r *Regression) toModel() (*model, error) {
+	if !r.Ready {
+		return nil, ErrRegressionRun
+	}
+	m := &model{
+		Observed:          r.observedName,
+		VarNames:          r.varNames,
+		NumVars:           r.numVars,
+		Transformed:       r.transformed,
+		Lambda:            r.lambda,
+		Coeffs:            r.GetCoeffs(),
+		R2:                jsonFloat(r.R2),
+		AdjustedR2:        jsonFloat(r.AdjustedR2),
+		WeightedR2:        jsonFloat(r.weightedR2),
+		VarianceObserved:  jsonFloat(r.VarianceObserved),
+		VariancePredicted: jsonFloat(r.VariancePredicted),
+	}
+	for _, cross := range r.crosses {
+		spec, ok := specifyCross(cross)
+		if !ok {
+			return nil, ErrNotSerializable
+		}
+		m.Crosses = append(m.Crosses, spec)
+	}
+	if r.family != nil {
+		for _, f := range families {
+			if f == r.family {
+				m.Family = f.Name
+			}
+		}
+		if m.Family == "" {
+			return nil, ErrNotSerializable
+		}
+	}
+	return m, nil
+}
+
+// fromModel replaces the regression with the deserialized one, which is ready to predict. The model is
+// validated first, as it may come from a corrupt input.
+func (r *Regression) fromModel(m *model) error {
+	if m.NumVars < 0 {
+		return ErrVariableCount
+	}
+	for _, spec := range m.Crosses {
+		if err := spec.checkVars(m.NumVars); err != nil {
+			return err
+		}
+	}
+	loaded := Regression{
+		observedName:      m.Observed,
+		varNames:          m.VarNames,
+		numVars:           m.NumVars,
+		transformed:       m.Transformed,
+		lambda:            m.Lambda,
+		R2:                float64(m.R2),
+		AdjustedR2:        float64(m.AdjustedR2),
+		weightedR2:        float64(m.WeightedR2),
+		VarianceObserved:  float64(m.VarianceObserved),
+		VariancePredicted: float64(m.VariancePredicted),
+	}
+	for _, spec := range m.Crosses {
+		cross, err := spec.Cross()
+		if err != nil {
+			return err
+		}
+		loaded.crosses = append(loaded.crosses, cross)
+	}
+	if m.Family != "" {
+		for _, f := range families {
+			if f.Name == m.Family {
+				loaded.family = f
+			}
+		}
+		if loaded.family == nil {
+			return ErrNotSerializable
+		}
+	}
+	if len(m.Coeffs) != len(loaded.features(make([]float64, m.NumVars)))+1 {
+		return ErrVariableCount
+	}
+	loaded.coeff = make(map[int]float64, len(m.Coeffs))
+	for i, c := range m.Coeffs {
+		loaded.coeff[i] = c
+	}
+	loaded.Ready = true
+	*r = loaded
+	return nil
+}
+
+// MarshalJSON serializes a regression which has run: its coefficients, the names of its variables,
+// its feature crosses, its response transformation or family and its fit statistics. The training data
+// points are not serialized. Only the built-in feature crosses and families can be serialized, others
+// yield ErrNotSerializable. The fit statistics which are not finite are null, and loaded as NaN.
+func (r *Regression) MarshalJSON() ([]byte, error) {
+	m, err := r.toModel()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON replaces the regression with one serialized by MarshalJSON, which is ready to predict.
+// It has no training data points, so that the methods requiring them return ErrNoTrainingData.
+func (r *Regression) UnmarshalJSON(data []byte) error {
+	var m model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	return r.fromModel(&m)
+}
+
+// Save writes a regression which has run to w in a binary format, encoded with gob and preceded by a
+// format version, so that it can be loaded back with Load. It serializes the same content as
+// MarshalJSON, and fails the same way.
+func (r *Regression) Save(w io.Writer) error {
+	m, err := r.toModel()
+	if err != nil {
+		return err
+	}
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(modelHeader{Format: modelFormat, Version: modelVersion}); err != nil {
+		return err
+	}
+	return enc.Encode(m)
+}
+
+// Load replaces the regression with one written by Save, which is ready to predict. It fails with
+// ErrModelVersion if the model was written in an unknown format version.
+func (r *Regression) Load(rd io.Reader) error {
+	dec := gob.NewDecoder(rd)
+	var header modelHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Format != modelFormat || header.Version != modelVersion {
+		return ErrModelVersion
+	}
+	var m model
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+	return r.fromModel(&m)
+}
@@ -0,0 +1,217 @@
+package regression
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func persistedRegression(t *testing.T) *Regression {
+	r := &Regression{}
+	for i := 1; i <= 20; i++ {
+		x, z := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: math.Exp(0.1*x + 0.5*z + 0.01*x*z + 0.05*math.Cos(float64(3*i))), Variables: []float64{x, z}})
+	}
+	r.SetObserved("Load")
+	r.SetVar(0, "Users")
+	r.AddCross(MultiplierCross(0, 1))
+	r.AddCross(ComposeCrosses(PowCross(0, 2), AbsCross(1)))
+	r.TransformResponse(0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestJSON(t *testing.T) {
+	if _, err := json.Marshal(&Regression{}); err == nil {
+		t.Error("Expected an error marshaling a regression which has not run")
+	}
+	r := persistedRegression(t)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Regression
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vars := range [][]float64{{3, 0.5}, {25, -1}} {
+		expected, _ := r.Predict(vars)
+		predicted, err := loaded.Predict(vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(predicted-expected) > 1e-12 {
+			t.Errorf("Expected prediction %v for %v, got %v", expected, vars, predicted)
+		}
+	}
+	if loaded.String() != r.String() {
+		t.Errorf("Expected equation %q, got %q", r.String(), loaded.String())
+	}
+	if loaded.R2 != r.R2 || loaded.AdjustedR2 != r.AdjustedR2 {
+		t.Errorf("Expected R^2 %v and %v, got %v and %v", r.R2, r.AdjustedR2, loaded.R2, loaded.AdjustedR2)
+	}
+	if _, err := loaded.Diagnostics(); err != ErrNoTrainingData {
+		t.Errorf("Expected %v, got %v", ErrNoTrainingData, err)
+	}
+
+	// a logistic regression keeps its family
+	logistic := &Regression{}
+	for i := 0; i < 40; i++ {
+		x := float64(i) / 4
+		logistic.Train(DataPoint{Observed: float64(i % 3 % 2), Variables: []float64{x}})
+	}
+	logistic.SetLogistic()
+	if err := logistic.Run(); err != nil {
+		t.Fatal(err)
+	}
+	data, err = json.Marshal(logistic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loadedLogistic Regression
+	if err := json.Unmarshal(data, &loadedLogistic); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := logistic.Predict([]float64{2})
+	if predicted, _ := loadedLogistic.Predict([]float64{2}); math.Abs(predicted-expected) > 1e-12 {
+		t.Errorf("Expected probability %v, got %v", expected, predicted)
+	}
+}
+
+func TestJSONNonFinite(t *testing.T) {
+	// as many data points as coefficients but one, and a constant response
+	exact := &Regression{}
+	exact.Train(
+		DataPoint{Observed: 1, Variables: []float64{0, 1}},
+		DataPoint{Observed: 3, Variables: []float64{1, 0}},
+		DataPoint{Observed: 4, Variables: []float64{2, 2}},
+	)
+	constant := &Regression{}
+	for i := 0; i < 10; i++ {
+		constant.Train(DataPoint{Observed: 5, Variables: []float64{float64(i)}})
+	}
+
+	for _, r := range []*Regression{exact, constant} {
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if finite(r.R2) && finite(r.AdjustedR2) {
+			t.Fatalf("Expected a statistic which is not finite, got R^2 %v and %v", r.R2, r.AdjustedR2)
+		}
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded Regression
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			t.Fatal(err)
+		}
+		for _, stat := range [][2]float64{{r.R2, loaded.R2}, {r.AdjustedR2, loaded.AdjustedR2}} {
+			// the statistics which are not finite are loaded as NaN
+			if stat[0] != stat[1] && !(!finite(stat[0]) && math.IsNaN(stat[1])) {
+				t.Errorf("Expected %v, got %v in %s", stat[0], stat[1], data)
+			}
+		}
+	}
+}
+
+func finite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+func TestJSONNotSerializable(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 10; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 1 + x + math.Sqrt(x), Variables: []float64{x}})
+	}
+	r.AddCross(&functionalCross{crossFn: func(vars []float64) []float64 {
+		return []float64{math.Sqrt(vars[0])}
+	}})
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.MarshalJSON(); err != ErrNotSerializable {
+		t.Errorf("Expected %v, got %v", ErrNotSerializable, err)
+	}
+
+	var loaded Regression
+	if err := json.Unmarshal([]byte(`{"num_vars": 1, "crosses": [{"kind": "Sqrt", "vars": [0]}], "coeffs": [1, 2, 3]}`), &loaded); err != ErrNotSerializable {
+		t.Errorf("Expected %v, got %v", ErrNotSerializable, err)
+	}
+	if err := json.Unmarshal([]byte(`{"num_vars": 2, "coeffs": [1, 2]}`), &loaded); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+
+	// corrupt models are rejected rather than panicking
+	for _, test := range []struct {
+		data     string
+		expected error
+	}{
+		{`{"num_vars": -1, "coeffs": [1]}`, ErrVariableCount},
+		{`{"num_vars": 1, "crosses": [{"kind": "Pow", "vars": [1], "power": 2}], "coeffs": [1, 2, 3]}`, ErrInvalidIndex},
+		{`{"num_vars": 1, "crosses": [{"kind": "Multiplier", "vars": [0, -1]}], "coeffs": [1, 2, 3]}`, ErrInvalidIndex},
+		{`{"num_vars": 1, "crosses": [{"kind": "Compose", "crosses": [{"kind": "Abs", "vars": [0]}, {"kind": "Abs", "vars": [3]}]}], "coeffs": [1, 2, 3, 4]}`, ErrInvalidIndex},
+	} {
+		if err := json.Unmarshal([]byte(test.data), &loaded); err != test.expected {
+			t.Errorf("Expected %v for %s, got %v", test.expected, test.data, err)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Regression{}).Save(&buf); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r := persistedRegression(t)
+	if err := r.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.Bytes()
+
+	var loaded Regression
+	if err := loaded.Load(bytes.NewReader(saved)); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := r.Predict([]float64{7, 0.2})
+	if predicted, err := loaded.Predict([]float64{7, 0.2}); err != nil || math.Abs(predicted-expected) > 1e-12 {
+		t.Errorf("Expected prediction %v, got %v (%v)", expected, predicted, err)
+	}
+	if loaded.String() != r.String() {
+		t.Errorf("Expected equation %q, got %q", r.String(), loaded.String())
+	}
+
+	// a model written in a future format version is rejected
+	buf.Reset()
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(modelHeader{Format: modelFormat, Version: modelVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Load(&buf); err != ErrModelVersion {
+		t.Errorf("Expected %v, got %v", ErrModelVersion, err)
+	}
+	if err := loaded.Load(bytes.NewReader(saved[:len(saved)/2])); err == nil {
+		t.Error("Expected an error loading a truncated model")
+	}
+
+	// a corrupt model is rejected rather than panicking
+	buf.Reset()
+	enc = gob.NewEncoder(&buf)
+	if err := enc.Encode(modelHeader{Format: modelFormat, Version: modelVersion}); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := &model{NumVars: 1, Crosses: []CrossSpec{{Kind: "Divide", Vars: []int{0, 2}}}, Coeffs: []float64{1, 2, 3}}
+	if err := enc.Encode(corrupt); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Load(&buf); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
@@ -0,0 +1,166 @@
+package regression
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// pmmlNode is an XML element of a PMML document.
+type pmmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []pmmlNode
+}
+
+// node returns a PMML element with the given name and attributes, given as name, value pairs.
+func node(name string, attrs ...string) pmmlNode {
+	n := pmmlNode{XMLName: xml.Name{Local: name}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: attrs[i]}, Value: attrs[i+1]})
+	}
+	return n
+}
+
+// with appends children to the element.
+func (n pmmlNode) with(children ...pmmlNode) pmmlNode {
+	n.Children = append(n.Children, children...)
+	return n
+}
+
+// apply returns a PMML Apply expression of function on args.
+func apply(function string, args ...pmmlNode) pmmlNode {
+	return node("Apply", "function", function).with(args...)
+}
+
+// constant returns a PMML Constant expression.
+func constant(v float64) pmmlNode {
+	n := node("Constant", "dataType", "double")
+	n.Text = formatFloat(v)
+	return n
+}
+
+// formatFloat formats v with the fewest digits which represent it exactly.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// pmmlCross returns the PMML expressions of the outputs of the feature cross described by spec, field
+// returning the reference to a variable.
+func pmmlCross(spec CrossSpec, field func(int) pmmlNode) ([]pmmlNode, error) {
+	switch spec.Kind {
+	case "Pow":
+		return []pmmlNode{apply("pow", field(spec.Vars[0]), constant(spec.Power))}, nil
+	case "Multiplier":
+		product := constant(1)
+		for k, v := range spec.Vars {
+			if k == 0 {
+				product = field(v)
+				continue
+			}
+			product = apply("*", product, field(v))
+		}
+		return []pmmlNode{product}, nil
+	case "Divide":
+		return []pmmlNode{apply("/", field(spec.Vars[0]), field(spec.Vars[1]))}, nil
+	case "Abs":
+		return []pmmlNode{apply("abs", field(spec.Vars[0]))}, nil
+	case "Identity":
+		return []pmmlNode{field(spec.Vars[0])}, nil
+	case "Sigmoid":
+		exp := apply("exp", apply("*", constant(-1), field(spec.Vars[0])))
+		return []pmmlNode{apply("/", constant(1), apply("+", constant(1), exp))}, nil
+	case "Compose":
+		var outputs []pmmlNode
+		for _, inner := range spec.Crosses {
+			exprs, err := pmmlCross(inner, field)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, exprs...)
+		}
+		return outputs, nil
+	default:
+		return nil, ErrNotSerializable
+	}
+}
+
+// ExportPMML writes the regression, which must have run, as a PMML 4.4 RegressionModel, so that it can
+// be scored by PMML engines. The feature crosses are written as derived fields, and the inverse of the
+// response transformation or of the link of the family as the normalization method of the model.
+// Only the built-in feature crosses and families and the log transformation of the response can be
+// exported, others yield ErrNotSerializable.
+func (r *Regression) ExportPMML(w io.Writer) error {
+	m, err := r.toModel()
+	if err != nil {
+		return err
+	}
+	normalization := "none"
+	switch {
+	case m.Family == Binomial.Name:
+		normalization = "logit"
+	case m.Family == Poisson.Name || m.Family == Gamma.Name:
+		normalization = "exp"
+	case m.Transformed && m.Lambda == 0:
+		normalization = "exp"
+	case m.Transformed:
+		return ErrNotSerializable
+	}
+
+	// the fields are named after the variables and the labels of the crosses, made unique
+	names := r.featureNames()
+	used := map[string]bool{r.GetObserved(): true}
+	for i, name := range names {
+		for k := 1; used[names[i]]; k++ {
+			names[i] = name + "_" + strconv.Itoa(k)
+		}
+		used[names[i]] = true
+	}
+
+	dictionary := node("DataDictionary", "numberOfFields", strconv.Itoa(r.numVars+1))
+	schema := node("MiningSchema")
+	for _, name := range names[:r.numVars] {
+		dictionary = dictionary.with(node("DataField", "name", name, "optype", "continuous", "dataType", "double"))
+		schema = schema.with(node("MiningField", "name", name))
+	}
+	dictionary = dictionary.with(node("DataField", "name", r.GetObserved(), "optype", "continuous", "dataType", "double"))
+	schema = schema.with(node("MiningField", "name", r.GetObserved(), "usageType", "target"))
+
+	transformations := node("TransformationDictionary")
+	field := func(i int) pmmlNode { return node("FieldRef", "field", names[i]) }
+	derived := r.numVars
+	for _, spec := range m.Crosses {
+		exprs, err := pmmlCross(spec, field)
+		if err != nil {
+			return err
+		}
+		for _, expr := range exprs {
+			transformations = transformations.with(node("DerivedField", "name", names[derived], "optype", "continuous", "dataType", "double").with(expr))
+			derived++
+		}
+	}
+
+	table := node("RegressionTable", "intercept", formatFloat(m.Coeffs[0]))
+	for j, name := range names {
+		table = table.with(node("NumericPredictor", "name", name, "coefficient", formatFloat(m.Coeffs[j+1])))
+	}
+	model := node("RegressionModel", "functionName", "regression", "normalizationMethod", normalization).with(schema, table)
+
+	doc := node("PMML", "xmlns", "http://www.dmg.org/PMML-4_4", "version", "4.4").with(
+		node("Header").with(node("Application", "name", "github.com/cocoonspace/regression")),
+		dictionary,
+		transformations,
+		model,
+	)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,135 @@
+package regression
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// element is a parsed XML element.
+type element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []element  `xml:",any"`
+}
+
+func (e element) attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (e element) find(name string) []element {
+	var found []element
+	for _, c := range e.Children {
+		if c.XMLName.Local == name {
+			found = append(found, c)
+		}
+		found = append(found, c.find(name)...)
+	}
+	return found
+}
+
+// scorePMML evaluates the subset of PMML written by ExportPMML for the given fields.
+func scorePMML(t *testing.T, doc element, fields map[string]float64) float64 {
+	var eval func(e element) float64
+	eval = func(e element) float64 {
+		switch e.XMLName.Local {
+		case "Constant":
+			v, _ := strconv.ParseFloat(e.Text, 64)
+			return v
+		case "FieldRef":
+			return fields[e.attr("field")]
+		}
+		args := make([]float64, len(e.Children))
+		for i, c := range e.Children {
+			args[i] = eval(c)
+		}
+		switch e.attr("function") {
+		case "pow":
+			return math.Pow(args[0], args[1])
+		case "*":
+			return args[0] * args[1]
+		case "/":
+			return args[0] / args[1]
+		case "+":
+			return args[0] + args[1]
+		case "abs":
+			return math.Abs(args[0])
+		case "exp":
+			return math.Exp(args[0])
+		}
+		t.Fatalf("Unexpected expression %s %s", e.XMLName.Local, e.attr("function"))
+		return 0
+	}
+	for _, derived := range doc.find("DerivedField") {
+		fields[derived.attr("name")] = eval(derived.Children[0])
+	}
+
+	table := doc.find("RegressionTable")[0]
+	y, _ := strconv.ParseFloat(table.attr("intercept"), 64)
+	for _, predictor := range table.Children {
+		c, _ := strconv.ParseFloat(predictor.attr("coefficient"), 64)
+		y += c * fields[predictor.attr("name")]
+	}
+	switch doc.find("RegressionModel")[0].attr("normalizationMethod") {
+	case "exp":
+		return math.Exp(y)
+	case "logit":
+		return 1 / (1 + math.Exp(-y))
+	}
+	return y
+}
+
+func TestExportPMML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Regression{}).ExportPMML(&buf); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r := persistedRegression(t)
+	r2 := &Regression{}
+	for _, d := range r.Data {
+		r2.Train(DataPoint{Observed: d.Observed, Variables: d.Variables})
+	}
+	r2.SetObserved("Load")
+	r2.SetVar(0, "Users")
+	// the identity cross is named like the variable it repeats
+	r2.AddCross(ComposeCrosses(SigmoidCross(1), IdentityCross(0), DivideCross(1, 0)))
+	if err := r2.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, model := range []*Regression{r, r2} {
+		buf.Reset()
+		if err := model.ExportPMML(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var doc element
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.XMLName.Local != "PMML" || doc.attr("version") != "4.4" {
+			t.Fatalf("Expected a PMML 4.4 document, got %s %s", doc.XMLName.Local, doc.attr("version"))
+		}
+		for _, vars := range [][]float64{{3, 0.5}, {12, -0.8}} {
+			expected, _ := model.Predict(vars)
+			if scored := scorePMML(t, doc, map[string]float64{"Users": vars[0], "x1": vars[1]}); math.Abs(scored-expected) > 1e-9*math.Abs(expected) {
+				t.Errorf("Expected PMML score %v for %v, got %v", expected, vars, scored)
+			}
+		}
+	}
+
+	r.TransformResponse(0.5)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ExportPMML(&buf); err != ErrNotSerializable {
+		t.Errorf("Expected %v, got %v", ErrNotSerializable, err)
+	}
+}
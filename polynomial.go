@@ -0,0 +1,33 @@
+package regression
+
+// FitPolynomial fits y = c0 + c1 x + c2 x² + ... + cd x^d, registering a PowCross for each power
+// above 1, and returns the regression once run. Coeff(i) is then the coefficient of x^i. It requires
+// degree+1 points, and at least 3 as Run does; with exactly degree+1 points the polynomial interpolates
+// them, which Overfit reports.
+func FitPolynomial(x, y []float64, degree int) (*Regression, error) {
+	if len(x) != len(y) {
+		return nil, ErrObservationCount
+	}
+	if degree < 1 {
+		return nil, ErrInvalidDegree
+	}
+	need := degree + 1
+	if need < 3 {
+		need = 3
+	}
+	if len(x) < need {
+		return nil, &InsufficientObservationsError{Have: len(x), Need: need}
+	}
+
+	r := &Regression{}
+	for i := range x {
+		r.Train(DataPoint{Observed: y[i], Variables: []float64{x[i]}})
+	}
+	for power := 2; power <= degree; power++ {
+		r.AddCross(PowCross(0, float64(power)))
+	}
+	if err := r.Run(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,54 @@
+package regression
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitPolynomial(t *testing.T) {
+	var x, y []float64
+	for i := -5; i <= 5; i++ {
+		v := float64(i)
+		x = append(x, v)
+		y = append(y, 3-2*v+0.5*v*v)
+	}
+
+	r, err := FitPolynomial(x, y, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{3, -2, 0.5}
+	for i, c := range expected {
+		if math.Abs(r.Coeff(i)-c) > 1e-9 {
+			t.Errorf("Expected coefficients %v, got %v", expected, r.GetCoeffs())
+		}
+	}
+	if val, _ := r.Predict([]float64{10}); math.Abs(val-33) > 1e-9 {
+		t.Errorf("Expected 33, got %v", val)
+	}
+
+	if _, err := FitPolynomial(x, y[1:], 2); err != ErrObservationCount {
+		t.Errorf("Expected %v, got %v", ErrObservationCount, err)
+	}
+	if _, err := FitPolynomial(x[:3], y[:3], 3); !errors.Is(err, ErrTooManyVars) {
+		t.Errorf("Expected %v, got %v", ErrTooManyVars, err)
+	}
+	if _, err := FitPolynomial(x[:2], y[:2], 1); !errors.Is(err, ErrTooManyVars) {
+		t.Errorf("Expected %v, got %v", ErrTooManyVars, err)
+	}
+
+	// a parabola through 3 points interpolates them
+	exact, err := FitPolynomial(x[:3], y[:3], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range expected {
+		if math.Abs(exact.Coeff(i)-c) > 1e-9 {
+			t.Errorf("Expected coefficients %v, got %v", expected, exact.GetCoeffs())
+		}
+	}
+	if !exact.Overfit() {
+		t.Error("Expected the interpolation to be flagged by Overfit")
+	}
+}
@@ -0,0 +1,97 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Settings of the iteratively reweighted least squares used for quantile regression.
+const (
+	maxIRLSIterations = 500
+	irlsTolerance     = 1e-10
+	// minIRLSResidual bounds the weight of the data points lying on the fit.
+	minIRLSResidual = 1e-8
+)
+
+// SetQuantile makes Run fit the q-quantile of the observed values conditional on the variables, e.g.
+// 0.5 for the median or 0.9 for the 90th percentile, instead of their mean. The coefficients minimise
+// the pinball loss Σ q max(eᵢ, 0) + (1-q) max(-eᵢ, 0), eᵢ being the residuals, which is solved by
+// iteratively reweighted least squares. It can't be combined with fixed or non-negative coefficients,
+// regularization, nor with Accumulate.
+func (r *Regression) SetQuantile(q float64) {
+	r.quantileFit = true
+	r.tau = q
+}
+
+// checkQuantile validates the quantile regression settings.
+func (r *Regression) checkQuantile() error {
+	if !r.quantileFit {
+		return nil
+	}
+	if r.tau <= 0 || r.tau >= 1 {
+		return ErrInvalidProbability
+	}
+	if len(r.fixed) > 0 || r.nonNegative || r.penalty != NoRegularization {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// solveQuantile returns the coefficients minimising the weighted pinball loss for the quantile tau.
+// Each iteration solves a weighted least squares problem where the weight of a data point is its
+// pinball loss divided by its squared residual under the previous coefficients. As there are many
+// iterations, they solve the normal equations, falling back to QR decomposition when XᵀWX is singular.
+func solveQuantile(variables, observed *mat.Dense, weights []float64, tau float64) []float64 {
+	n, p := variables.Dims()
+	c := solveConstrained(variables, observed, weights, nil)
+	irls := make([]float64, n)
+	for iter := 0; iter < maxIRLSIterations; iter++ {
+		for i := range irls {
+			e := observed.At(i, 0) - mat.Dot(variables.RowView(i), mat.NewVecDense(p, c))
+			irls[i] = tau
+			if e < 0 {
+				irls[i] = 1 - tau
+			}
+			irls[i] /= math.Max(math.Abs(e), minIRLSResidual)
+			if weights != nil {
+				irls[i] *= weights[i]
+			}
+		}
+		updated := solveNormalEquations(variables, observed, irls)
+		if updated == nil {
+			updated = solveConstrained(variables, observed, irls, nil)
+		}
+		var change float64
+		for j := range c {
+			change = math.Max(change, math.Abs(updated[j]-c[j]))
+		}
+		c = updated
+		if change < irlsTolerance {
+			break
+		}
+	}
+	return c
+}
+
+// solveNormalEquations returns the solution of XᵀWX c = XᵀWy using Cholesky decomposition, or nil if
+// XᵀWX is not positive definite.
+func solveNormalEquations(variables, observed *mat.Dense, weights []float64) []float64 {
+	n, p := variables.Dims()
+	xtwx := mat.NewSymDense(p, nil)
+	xtwy := mat.NewVecDense(p, nil)
+	for i := 0; i < n; i++ {
+		row := variables.RowView(i)
+		xtwx.SymRankOne(xtwx, weights[i], row)
+		xtwy.AddScaledVec(xtwy, weights[i]*observed.At(i, 0), row)
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(xtwx) {
+		return nil
+	}
+	c := mat.NewVecDense(p, nil)
+	if err := chol.SolveVecTo(c, xtwy); err != nil {
+		return nil
+	}
+	return c.RawVector().Data
+}
@@ -0,0 +1,47 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSetQuantile(t *testing.T) {
+	// the spread of the observed values grows with x, so that the quantiles have different slopes
+	rnd := rand.New(rand.NewSource(1))
+	train := func(r *Regression) {
+		for i := 0; i < 2000; i++ {
+			x := rnd.Float64() * 10
+			r.Train(DataPoint{Observed: 1 + 2*x + rnd.Float64()*x, Variables: []float64{x}})
+		}
+	}
+
+	for _, q := range []float64{0.5, 0.9} {
+		r := &Regression{}
+		train(r)
+		r.SetQuantile(q)
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(r.Coeff(0)-1) > 0.1 || math.Abs(r.Coeff(1)-(2+q)) > 0.05 {
+			t.Errorf("Expected coefficients close to [1 %v] for quantile %v, got %v", 2+q, q, r.GetCoeffs())
+		}
+
+		var below int
+		for _, d := range r.Data {
+			if d.Observed < d.Predicted {
+				below++
+			}
+		}
+		if share := float64(below) / float64(len(r.Data)); math.Abs(share-q) > 0.03 {
+			t.Errorf("Expected a share %v of the observations below the fit, got %v", q, share)
+		}
+	}
+
+	r := &Regression{}
+	train(r)
+	r.SetQuantile(1)
+	if err := r.Run(); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+}
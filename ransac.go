@@ -0,0 +1,89 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// RunRANSAC fits the regression with the RANSAC algorithm, for data sets with many gross outliers.
+// Each of the iterations fits the coefficients on a random minimal subset of the data points, and
+// counts the data points whose residual is within threshold of it. The fit with the largest consensus
+// is then refined by running the regression on its inliers only, the other data points being flagged
+// as Outlier. All the data points are kept, so that the regression can be run again on them, and
+// Predicted and Error are set for all of them. The statistics of the regression, such as R2, and the
+// methods based on the design matrix and the residuals describe the fit on the inliers; Update and
+// Downdate fail with ErrUnsupportedFit when there are outliers. The threshold is on the scale of the
+// fit, that is on the transformed observed values when TransformResponse is used.
+func (r *Regression) RunRANSAC(threshold float64, iterations int, seed int64) error {
+	if !r.initialised {
+		return ErrNotEnoughData
+	}
+	if r.stream != nil {
+		return ErrNoTrainingData
+	}
+	if threshold <= 0 || iterations < 1 {
+		return ErrInvalidRANSAC
+	}
+	r.applyCrosses()
+	variables, observed := r.designMatrix()
+	n, p := variables.Dims()
+	if n < p {
+		return &InsufficientObservationsError{Have: n, Need: p}
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	var best []bool
+	bestCount, bestSSE := 0, math.Inf(1)
+	for iter := 0; iter < iterations; iter++ {
+		sample := rnd.Perm(n)[:p]
+		c := solveLeastSquares(subsetRows(variables, sample), subsetRows(observed, sample))
+
+		inliers := make([]bool, n)
+		var count int
+		var sse float64
+		for i := 0; i < n; i++ {
+			e := observed.At(i, 0) - mat.Dot(variables.RowView(i), mat.NewVecDense(p, c))
+			// a degenerate sample gives non-finite coefficients, hence no inliers
+			if math.Abs(e) <= threshold {
+				inliers[i] = true
+				count++
+				sse += e * e
+			}
+		}
+		if count > bestCount || (count == bestCount && sse < bestSSE) {
+			best, bestCount, bestSSE = inliers, count, sse
+		}
+	}
+	if bestCount < p {
+		return ErrNotEnoughData
+	}
+
+	all := r.Data
+	consensus := make([]DataPoint, 0, bestCount)
+	for i := range all {
+		all[i].Outlier = !best[i]
+		if best[i] {
+			consensus = append(consensus, all[i])
+		}
+	}
+	r.Data = consensus
+	err := r.Run()
+	r.Data = all
+	if err != nil {
+		return err
+	}
+	r.calcPredicted()
+	return nil
+}
+
+// subsetRows returns a copy of the given rows of m.
+func subsetRows(m *mat.Dense, rows []int) *mat.Dense {
+	_, c := m.Dims()
+	subset := mat.NewDense(len(rows), c, nil)
+	for i, row := range rows {
+		subset.SetRow(i, m.RawRowView(row))
+	}
+	return subset
+}
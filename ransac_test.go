@@ -0,0 +1,61 @@
+package regression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRunRANSAC(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 50; i++ {
+		x := float64(i)
+		observed := 3 + 2*x + 0.1*math.Sin(x)
+		// 40% of garbage measurements
+		if i%5 < 2 {
+			observed = 300 * math.Cos(x)
+		}
+		r.Train(DataPoint{Observed: observed, Variables: []float64{x}})
+	}
+
+	if err := r.RunRANSAC(0, 10, 1); err != ErrInvalidRANSAC {
+		t.Errorf("Expected %v, got %v", ErrInvalidRANSAC, err)
+	}
+	if err := r.RunRANSAC(1, 100, 1); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.Coeff(0)-3) > 0.1 || math.Abs(r.Coeff(1)-2) > 0.01 {
+		t.Errorf("Expected coefficients close to [3 2], got %v", r.GetCoeffs())
+	}
+	for i, d := range r.Data {
+		if d.Outlier != (i%5 < 2) {
+			t.Errorf("Expected data point %d to be flagged outlier %v", i, i%5 < 2)
+		}
+		if math.Abs(d.Predicted-(3+2*d.Variables[0])) > 0.2 {
+			t.Errorf("Expected data point %d to be predicted by the consensus fit, got %v", i, d.Predicted)
+		}
+	}
+	if r.R2 < 0.99 {
+		t.Errorf("Expected the R2 of the inliers, got %v", r.R2)
+	}
+	design, err := r.DesignMatrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows, _ := design.Dims(); len(r.Data) != 50 || rows != 30 {
+		t.Errorf("Expected the 50 data points to be kept and the 30 inliers to be fitted, got %d and %d", len(r.Data), rows)
+	}
+	if err := r.Update(DataPoint{Observed: 1, Variables: []float64{1}}); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+	if err := r.Downdate(0); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+
+	// the regression can be run again on all the data points
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(DataPoint{Observed: 1, Variables: []float64{1}}); err != nil {
+		t.Error(err)
+	}
+}
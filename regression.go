@@ -2,7 +2,13 @@ package regression
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -14,26 +20,172 @@ var (
 	ErrTooManyVars = errors.New("not enough observations to support this many variables")
 	// ErrRegressionRun signals that the Run method has not been run yet.
 	ErrRegressionRun = errors.New("regression has not run yet")
+	// ErrInvalidBandwidth signals that a kernel bandwidth is not strictly positive.
+	ErrInvalidBandwidth = errors.New("bandwidth must be positive")
+	// ErrZeroVariance signals that all the observed values are equal, leaving nothing to explain.
+	ErrZeroVariance = errors.New("observed values have zero variance")
+	// ErrNotSingleVariable signals that a method only applies to data points with exactly one variable.
+	ErrNotSingleVariable = errors.New("exactly one variable is required")
+	// ErrVariableCount signals that data points don't have the expected number of variables.
+	ErrVariableCount = errors.New("unexpected number of variables")
+	// ErrNonPositiveObserved signals that the response transformation requires positive observed values.
+	ErrNonPositiveObserved = errors.New("observed values must be positive to be transformed")
+	// ErrSingularMatrix signals that the variables are collinear, so that (XᵀX)⁻¹ does not exist.
+	ErrSingularMatrix = errors.New("design matrix is singular")
+	// ErrUnknownEstimator signals that the requested standard errors estimator is not supported.
+	ErrUnknownEstimator = errors.New("unknown standard errors estimator")
+	// ErrInvalidProbability signals that a probability, confidence level or quantile is not between 0 and 1.
+	ErrInvalidProbability = errors.New("probability must be between 0 and 1")
+	// ErrNegativePenalty signals that a regularization penalty is negative.
+	ErrNegativePenalty = errors.New("penalty must not be negative")
+	// ErrUnsupportedFit signals that an operation is not supported with the configured fit options.
+	ErrUnsupportedFit = errors.New("operation not supported by the configured fit")
+	// ErrNoVariableNames signals that no variable name was set with SetVar.
+	ErrNoVariableNames = errors.New("variable names are not set")
+	// ErrUnknownColumn signals that a column name is not in the header.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrMismatchedCrosses signals that two regressions don't have the same feature crosses.
+	ErrMismatchedCrosses = errors.New("feature crosses differ")
+	// ErrInvalidDegree signals that a polynomial degree is lower than 1.
+	ErrInvalidDegree = errors.New("degree must be at least 1")
+	// ErrNoTrainingData signals that the training data points were not retained, see Accumulate.
+	ErrNoTrainingData = errors.New("training data points were not retained")
+	// ErrObservationCount signals that a slice doesn't have one element per observation.
+	ErrObservationCount = errors.New("unexpected number of observations")
+	// ErrInvalidIndex signals that a variable index, data point index or lag is out of range.
+	ErrInvalidIndex = errors.New("index out of range")
+	// ErrInvalidRatio signals that the L1 ratio of an elastic net is not between 0 and 1.
+	ErrInvalidRatio = errors.New("ratio must be between 0 and 1")
+	// ErrNegativeWeight signals that a data point has a negative weight.
+	ErrNegativeWeight = errors.New("weight must not be negative")
+	// ErrInvalidRANSAC signals that the RANSAC threshold or number of iterations is not positive.
+	ErrInvalidRANSAC = errors.New("RANSAC threshold and iterations must be positive")
+	// ErrObservedDomain signals that observed values are outside the domain of the distribution fitted,
+	// e.g. not between 0 and 1 for a logistic regression.
+	ErrObservedDomain = errors.New("observed values outside the domain of the distribution")
+	// ErrInvalidBound signals that the lower bound of a coefficient is above its upper bound.
+	ErrInvalidBound = errors.New("lower bound above upper bound")
+	// ErrInvalidCovariance signals that an error covariance matrix is not positive definite.
+	ErrInvalidCovariance = errors.New("covariance matrix is not positive definite")
+	// ErrInvalidPrior signals that the shape or the scale of an inverse gamma prior is negative.
+	ErrInvalidPrior = errors.New("prior shape and scale must not be negative")
+	// ErrUnknownCriterion signals that a model selection criterion is not supported.
+	ErrUnknownCriterion = errors.New("unknown selection criterion")
+	// ErrTooManyFeatures signals that there are too many features for an exhaustive search.
+	ErrTooManyFeatures = errors.New("too many features for an exhaustive search")
+	// ErrInvalidFolds signals that the number of cross-validation folds is not between 2 and the number
+	// of data points.
+	ErrInvalidFolds = errors.New("invalid number of folds")
+	// ErrIllConditioned signals that the condition number of the design matrix exceeds the threshold set
+	// with SetMaxConditionNumber.
+	ErrIllConditioned = errors.New("design matrix is ill-conditioned")
+	// ErrNotSerializable signals that a model can't be serialized, because one of its feature crosses
+	// or its family is not a built-in one.
+	ErrNotSerializable = errors.New("model can't be serialized")
+	// ErrModelVersion signals that a saved model has an unknown format version.
+	ErrModelVersion = errors.New("unknown model format version")
+	// ErrInvalidField signals that a field of a JSON record is missing or is not a number.
+	ErrInvalidField = errors.New("field is missing or not a number")
 )
 
+// InsufficientObservationsError signals that there are fewer observations than the Need required
+// to fit the offset, the variables and the feature crosses. It matches ErrTooManyVars with errors.Is.
+type InsufficientObservationsError struct {
+	Have int
+	Need int
+}
+
+func (e *InsufficientObservationsError) Error() string {
+	return fmt.Sprintf("%s: have %d observations, need at least %d", ErrTooManyVars, e.Have, e.Need)
+}
+
+// Is reports whether target is ErrTooManyVars.
+func (e *InsufficientObservationsError) Is(target error) bool {
+	return target == ErrTooManyVars
+}
+
+// IllConditionedError signals that the Condition number of the design matrix exceeds the Threshold set
+// with SetMaxConditionNumber. It matches ErrIllConditioned with errors.Is.
+type IllConditionedError struct {
+	Condition float64
+	Threshold float64
+}
+
+func (e *IllConditionedError) Error() string {
+	return fmt.Sprintf("%s: condition number %g above %g", ErrIllConditioned, e.Condition, e.Threshold)
+}
+
+// Is reports whether target is ErrIllConditioned.
+func (e *IllConditionedError) Is(target error) bool {
+	return target == ErrIllConditioned
+}
+
+// minResidualDF is the number of residual degrees of freedom below which a fit is flagged by Overfit.
+const minResidualDF = 5
+
 // Regression is the exposed data structure for interacting with the API.
+// R2 is always the unweighted coefficient of determination, see WeightedR2 when weights are used.
+// AdjustedR2 is R2 penalized for the number of variables and feature crosses p, 1 - (1-R2)(n-1)/(n-p-1),
+// so that it only increases when a new feature improves the fit more than expected by chance.
 type Regression struct {
 	Data              []DataPoint
 	coeff             map[int]float64
 	R2                float64
+	AdjustedR2        float64
+	weightedR2        float64
 	VarianceObserved  float64
 	VariancePredicted float64
 	initialised       bool
 	crosses           []featureCross
+	transformed       bool
+	lambda            float64
+	variables         *mat.Dense
+	observed          *mat.Dense
+	xtxInv            *mat.SymDense
+	weights           []float64
+	numVars           int
+	fixed             map[int]float64
+	centering         bool
+	maxCondition      float64
+	covEstimator      string
+	stream            *accumulator
+	varNames          map[int]string
+	observedName      string
+	overfit           bool
+	cache             *crossCache
+	nonNegative       bool
+	nonNegativeOffset bool
+	bounds            map[int]bound
+	errorCov          *mat.Cholesky
+	penalty           Regularization
+	penaltyLambda     float64
+	l1Ratio           float64
+	quantileFit       bool
+	tau               float64
+	family            *Family
 	Ready             bool
 }
 
+// DataPoint is a single observation. Weight sets the relative importance of the observation in the
+// least squares fit, zero being treated as 1 so that unweighted data points need not set it. A weight
+// of k is equivalent to k copies of the data point, and negative weights are rejected.
+// Outlier is set by RunRANSAC for the data points left out of the consensus fit.
 type DataPoint struct {
 	Observed  float64
 	Variables []float64
 	Crosses   []float64
 	Predicted float64
 	Error     float64
+	Weight    float64
+	Outlier   bool
+}
+
+// weight returns the effective weight of the data point.
+func (d DataPoint) weight() float64 {
+	if d.Weight == 0 {
+		return 1
+	}
+	return d.Weight
 }
 
 // DataPoints is a slice of DataPoint
@@ -46,21 +198,399 @@ func (r *Regression) Predict(vars []float64) (float64, error) {
 		return 0, ErrRegressionRun
 	}
 
-	// apply any features crosses to vars
-	for _, cross := range r.crosses {
-		vars = append(vars, cross.Calculate(vars)...)
+	return r.predict(r.features(vars)), nil
+}
+
+// PredictBatch returns the predictions for each row of variables.
+func (r *Regression) PredictBatch(rows [][]float64) ([]float64, error) {
+	if err := r.checkRows(rows); err != nil {
+		return nil, err
+	}
+	predictions := make([]float64, len(rows))
+	for i, vars := range rows {
+		predictions[i] = r.predict(r.features(vars))
 	}
+	return predictions, nil
+}
 
+// PredictParallel is like PredictBatch, but spreads the rows across workers goroutines.
+// A workers count below 1 defaults to the number of CPUs.
+func (r *Regression) PredictParallel(rows [][]float64, workers int) ([]float64, error) {
+	if err := r.checkRows(rows); err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	predictions := make([]float64, len(rows))
+	chunk := (len(rows) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(rows); start += chunk {
+		end := start + chunk
+		if end > len(rows) {
+			end = len(rows)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				predictions[i] = r.predict(r.features(rows[i]))
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return predictions, nil
+}
+
+// checkRows verifies that the regression has run and that all rows have the trained number of variables.
+func (r *Regression) checkRows(rows [][]float64) error {
+	if !r.Ready {
+		return ErrRegressionRun
+	}
+	for _, vars := range rows {
+		if len(vars) != r.numVars {
+			return ErrVariableCount
+		}
+	}
+	return nil
+}
+
+// PredictRaw is like Predict, but expects the feature crosses to be already applied: expandedVars
+// holds the variables followed by the outputs of the feature crosses, in registration order.
+// This avoids recomputing expensive crosses when the caller already has them.
+func (r *Regression) PredictRaw(expandedVars []float64) (float64, error) {
+	if !r.Ready {
+		return 0, ErrRegressionRun
+	}
+	if len(expandedVars) != len(r.coeff)-1 {
+		return 0, ErrVariableCount
+	}
+	return r.predict(expandedVars), nil
+}
+
+// predict computes the prediction for variables to which the feature crosses were applied.
+func (r *Regression) predict(features []float64) float64 {
 	p := r.Coeff(0)
-	for j, val := range vars {
+	for j, val := range features {
 		p += r.Coeff(j+1) * val
 	}
-	return p, nil
+	return r.inverseResponse(p)
+}
+
+// Explain returns the contribution of each coefficient to the prediction for the inputed features,
+// starting with the offset, followed by the variables and the feature crosses.
+// The contributions sum to the value returned by Predict, before the inverse of the response
+// transformation is applied if TransformResponse was used.
+func (r *Regression) Explain(vars []float64) ([]float64, error) {
+	if !r.Ready {
+		return nil, ErrRegressionRun
+	}
+
+	features := r.features(vars)
+	contributions := make([]float64, 0, len(features)+1)
+	contributions = append(contributions, r.Coeff(0))
+	for j, val := range features {
+		contributions = append(contributions, r.Coeff(j+1)*val)
+	}
+	return contributions, nil
+}
+
+// MarginalEffectAtMean returns the derivative of the prediction with respect to variable varIndex,
+// the variables being at their training means. Unlike the coefficient of the variable, it accounts
+// for the variable flowing through the feature crosses. It is computed by central differences.
+func (r *Regression) MarginalEffectAtMean(varIndex int) (float64, error) {
+	if !r.Ready {
+		return 0, ErrRegressionRun
+	}
+	means, err := r.VariableMeans()
+	if err != nil {
+		return 0, err
+	}
+	if varIndex < 0 || varIndex >= len(means) {
+		return 0, ErrInvalidIndex
+	}
+
+	h := 1e-5 * math.Max(1, math.Abs(means[varIndex]))
+	vars := append([]float64(nil), means...)
+	vars[varIndex] = means[varIndex] + h
+	upper, _ := r.Predict(vars)
+	vars[varIndex] = means[varIndex] - h
+	lower, _ := r.Predict(vars)
+	return (upper - lower) / (2 * h), nil
+}
+
+// PredictLocal returns a locally weighted prediction for the inputed features: every training point
+// is weighted by a Gaussian kernel of its euclidean distance to vars, and the model is refitted
+// with these weights. The bandwidth is the standard deviation of the kernel. The refit is an ordinary
+// least squares one, so that the regression must not have been configured with fixed coefficients or
+// any other fit.
+func (r *Regression) PredictLocal(vars []float64, bandwidth float64) (float64, error) {
+	if err := r.requireData(); err != nil {
+		return 0, err
+	}
+	if len(vars) != r.numVars {
+		return 0, ErrVariableCount
+	}
+	if bandwidth <= 0 {
+		return 0, ErrInvalidBandwidth
+	}
+	if !r.leastSquares() || len(r.fixed) > 0 {
+		return 0, ErrUnsupportedFit
+	}
+
+	weights := make([]float64, len(r.Data))
+	for i, d := range r.Data {
+		var dist float64
+		for j, val := range d.Variables {
+			dist += (val - vars[j]) * (val - vars[j])
+		}
+		weights[i] = d.weight() * math.Exp(-dist/(2*bandwidth*bandwidth))
+	}
+
+	variables, observed := r.designMatrix()
+	c := solveLeastSquares(weightRows(variables, weights), weightRows(observed, weights))
+
+	p := c[0]
+	for j, val := range r.features(vars) {
+		p += c[j+1] * val
+	}
+	return r.inverseResponse(p), nil
+}
+
+// features returns the variables followed by the output of the feature crosses.
+func (r *Regression) features(vars []float64) []float64 {
+	if len(r.crosses) == 0 {
+		return vars
+	}
+	f := make([]float64, len(vars), len(vars)+len(r.crosses))
+	copy(f, vars)
+	if r.cache != nil {
+		return r.cache.features(f, r.crosses)
+	}
+	for _, cross := range r.crosses {
+		f = append(f, cross.Calculate(vars)...)
+	}
+	return f
 }
 
 // AddCross registers a feature cross to be applied to the data points.
+// The outputs of the crosses follow the variables in the coefficients, in registration order.
 func (r *Regression) AddCross(cross featureCross) {
 	r.crosses = append(r.crosses, cross)
+	if r.cache != nil {
+		r.cache.reset()
+	}
+}
+
+// CrossDependencies returns, for each registered feature cross, the indices of the variables it
+// depends on, or nil if the cross doesn't implement a Dependencies() []int method.
+func (r *Regression) CrossDependencies() [][]int {
+	deps := make([][]int, len(r.crosses))
+	for i, cross := range r.crosses {
+		if dependent, ok := cross.(dependentCross); ok {
+			deps[i] = dependent.Dependencies()
+		}
+	}
+	return deps
+}
+
+// SetCrossCache memoizes the outputs of the feature crosses for up to size distinct inputs, so that
+// predicting the same inputs repeatedly doesn't recompute expensive crosses. The cache is emptied when
+// full. A size of 0 disables the cache, which is the default.
+func (r *Regression) SetCrossCache(size int) {
+	if size <= 0 {
+		r.cache = nil
+		return
+	}
+	r.cache = &crossCache{size: size}
+}
+
+// FixCoeff constrains coefficient i to value, known a priori, the other coefficients being fitted
+// around it. Index 0 is the offset, and the following indices are the variables then the feature crosses.
+func (r *Regression) FixCoeff(i int, value float64) {
+	if r.fixed == nil {
+		r.fixed = map[int]float64{}
+	}
+	r.fixed[i] = value
+}
+
+// SetNonNegative enables or disables constraining the coefficients of the variables and the feature
+// crosses to be non-negative, the offset being left unconstrained. Run then solves a non-negative least
+// squares problem with the Lawson-Hanson active set method, and fixed coefficients are ignored.
+func (r *Regression) SetNonNegative(nonNegative bool) {
+	r.nonNegative = nonNegative
+}
+
+// SetNonNegativeOffset enables or disables constraining the offset to be non-negative too, when
+// SetNonNegative is enabled, so that all the coefficients are.
+func (r *Regression) SetNonNegativeOffset(nonNegative bool) {
+	r.nonNegativeOffset = nonNegative
+}
+
+// SetMaxConditionNumber makes Run fail with an IllConditionedError when the condition number of the
+// design matrix, see ConditionNumber, exceeds threshold, rather than return coefficients which are
+// numerically unreliable. A threshold of 0, the default, disables the check, which does not apply to
+// accumulated data points.
+func (r *Regression) SetMaxConditionNumber(threshold float64) {
+	r.maxCondition = threshold
+}
+
+// SetCentering enables or disables centering the variables and the observations on their means before
+// fitting, the offset being recovered afterwards. This improves the numerical accuracy when the offset
+// is large compared to the variations of the data. It is disabled by default.
+func (r *Regression) SetCentering(centering bool) {
+	r.centering = centering
+}
+
+// TransformResponse makes the regression fit a Box-Cox transformation of the observed values,
+// (y^lambda - 1) / lambda, or log(y) when lambda is 0. This linearizes skewed responses, and requires
+// all observed values to be positive. Predict applies the inverse transformation, so that predictions
+// are on the original scale.
+func (r *Regression) TransformResponse(lambda float64) {
+	r.transformed = true
+	r.lambda = lambda
+}
+
+// response applies the response transformation, if any, to an observed value.
+func (r *Regression) response(observed float64) float64 {
+	switch {
+	case !r.transformed:
+		return observed
+	case r.lambda == 0:
+		return math.Log(observed)
+	default:
+		return (math.Pow(observed, r.lambda) - 1) / r.lambda
+	}
+}
+
+// inverseResponse maps a value fitted on the transformed scale back to the original scale, or a linear
+// predictor to the mean of a generalized linear model.
+func (r *Regression) inverseResponse(p float64) float64 {
+	switch {
+	case r.family != nil:
+		return r.family.InverseLink(p)
+	case !r.transformed:
+		return p
+	case r.lambda == 0:
+		return math.Exp(p)
+	default:
+		return math.Pow(r.lambda*p+1, 1/r.lambda)
+	}
+}
+
+// AddAllInteractions registers a MultiplierCross for every pair of the numVars first variables.
+// Pairs which are already crossed are not registered twice.
+func (r *Regression) AddAllInteractions(numVars int) {
+	existing := map[string]bool{}
+	for _, cross := range r.crosses {
+		for _, name := range labelCross(cross, r.varName) {
+			existing[name] = true
+		}
+	}
+
+	for i := 0; i < numVars; i++ {
+		for j := i + 1; j < numVars; j++ {
+			cross := MultiplierCross(i, j)
+			if name := labelCross(cross, r.varName)[0]; !existing[name] {
+				r.AddCross(cross)
+				existing[name] = true
+			}
+		}
+	}
+}
+
+// SetObserved sets the name of the observed value, used to report the fitted equation.
+func (r *Regression) SetObserved(name string) {
+	r.observedName = name
+}
+
+// GetObserved returns the name of the observed value, y if not set with SetObserved.
+func (r *Regression) GetObserved() string {
+	if r.observedName == "" {
+		return "y"
+	}
+	return r.observedName
+}
+
+// SetVar sets the name of variable i, used to label the coefficients.
+func (r *Regression) SetVar(i int, name string) {
+	if r.varNames == nil {
+		r.varNames = map[int]string{}
+	}
+	r.varNames[i] = name
+}
+
+// GetVar returns the name of variable i, x0, x1... if not set with SetVar.
+func (r *Regression) GetVar(i int) string {
+	return r.varName(i)
+}
+
+// varName returns the label of variable i, its name if set.
+func (r *Regression) varName(i int) string {
+	if name, ok := r.varNames[i]; ok {
+		return name
+	}
+	return "x" + strconv.Itoa(i)
+}
+
+// featureNames returns the labels of the variables followed by those of the feature crosses outputs.
+func (r *Regression) featureNames() []string {
+	names := make([]string, 0, len(r.coeff))
+	for i := 0; i < r.numVars; i++ {
+		names = append(names, r.varName(i))
+	}
+	for k, cross := range r.crosses {
+		if crossNames := labelCross(cross, r.varName); crossNames != nil {
+			names = append(names, crossNames...)
+			continue
+		}
+		outputs := len(cross.Calculate(make([]float64, r.numVars)))
+		for o := 0; o < outputs; o++ {
+			names = append(names, "cross"+strconv.Itoa(k)+"."+strconv.Itoa(o))
+		}
+	}
+	return names
+}
+
+// String returns the fitted equation, e.g. "Murders = 3.21 + 0.0001*Population - 1.2*Poverty", using
+// the names set with SetObserved and SetVar and the labels of the feature crosses. The left-hand side
+// is the transformed observed value when TransformResponse or SetFamily are used.
+func (r *Regression) String() string {
+	if !r.Ready {
+		return ErrRegressionRun.Error()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(r.responseName())
+	sb.WriteString(" = ")
+	sb.WriteString(strconv.FormatFloat(r.Coeff(0), 'g', 4, 64))
+	for j, name := range r.featureNames() {
+		c := r.Coeff(j + 1)
+		if c < 0 {
+			sb.WriteString(" - ")
+		} else {
+			sb.WriteString(" + ")
+		}
+		sb.WriteString(strconv.FormatFloat(math.Abs(c), 'g', 4, 64))
+		sb.WriteString("*")
+		sb.WriteString(name)
+	}
+	return sb.String()
+}
+
+// responseName returns the label of the observed value on the scale of the fit.
+func (r *Regression) responseName() string {
+	switch {
+	case r.family != nil && r.family != Gaussian:
+		return "link(" + r.GetObserved() + ")"
+	case !r.transformed:
+		return r.GetObserved()
+	case r.lambda == 0:
+		return "log(" + r.GetObserved() + ")"
+	default:
+		return fmt.Sprintf("boxcox(%s, %g)", r.GetObserved(), r.lambda)
+	}
 }
 
 // Train the regression with some data points.
@@ -71,18 +601,55 @@ func (r *Regression) Train(d ...DataPoint) {
 	}
 }
 
-// Apply any feature crosses, generating new observations and updating the data points, as well as
-// populating variable names for the feature crosses.
-func (r *Regression) applyCrosses() {
-	if len(r.crosses) == 0 {
-		return
+// Merge adds the training data points of other to the regression, which must then be run again.
+// Both regressions must have the same number of variables and the same feature crosses.
+func (r *Regression) Merge(other *Regression) error {
+	if r.stream != nil || other.stream != nil {
+		return ErrNoTrainingData
 	}
-	for _, p := range r.Data {
-		if len(p.Crosses) > 0 {
-			continue
+	if len(r.Data) > 0 && len(other.Data) > 0 && len(r.Data[0].Variables) != len(other.Data[0].Variables) {
+		return ErrVariableCount
+	}
+	if !sameCrosses(r.crosses, other.crosses) {
+		return ErrMismatchedCrosses
+	}
+	r.Train(other.Data...)
+	r.Ready = false
+	return nil
+}
+
+// sameCrosses reports whether two lists of feature crosses compute the same features, comparing
+// their names when they implement namedCross.
+func sameCrosses(a, b []featureCross) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	label := func(i int) string { return strconv.Itoa(i) }
+	for i := range a {
+		na, nb := labelCross(a[i], label), labelCross(b[i], label)
+		switch {
+		case na != nil && nb != nil:
+			if strings.Join(na, ",") != strings.Join(nb, ",") {
+				return false
+			}
+		case na != nil || nb != nil:
+			return false
+		case !reflect.TypeOf(a[i]).Comparable() || a[i] != b[i]:
+			return false
 		}
+	}
+	return true
+}
+
+// Apply any feature crosses, generating new observations and updating the data points, as well as
+// populating variable names for the feature crosses. The outputs are always recomputed, as crosses may
+// have been registered since the last run, and data points may come from another regression.
+func (r *Regression) applyCrosses() {
+	for i := range r.Data {
+		p := &r.Data[i]
+		p.Crosses = nil
 		for _, c := range r.crosses {
-			p.Crosses = c.Calculate(p.Variables)
+			p.Crosses = append(p.Crosses, c.Calculate(p.Variables)...)
 		}
 	}
 }
@@ -95,34 +662,262 @@ func (r *Regression) Run() error {
 	if !r.initialised {
 		return ErrNotEnoughData
 	}
+	if err := r.checkStream(); err != nil {
+		return err
+	}
+	if err := r.checkPenalty(); err != nil {
+		return err
+	}
+	if err := r.checkQuantile(); err != nil {
+		return err
+	}
+	if err := r.checkFamily(); err != nil {
+		return err
+	}
+	if err := r.checkBounds(); err != nil {
+		return err
+	}
+	if err := r.checkErrorCovariance(); err != nil {
+		return err
+	}
+	if r.stream != nil {
+		// fold in the data points trained since the last call to Accumulate
+		if err := r.Accumulate(); err != nil {
+			return err
+		}
+		return r.runAccumulated()
+	}
 
 	// apply any features crosses
 	r.applyCrosses()
-	r.Ready = true
 
 	observations := len(r.Data)
 	numOfvars := len(r.Data[0].Variables) + len(r.Data[0].Crosses)
 
 	if observations < (numOfvars + 1) {
-		return ErrTooManyVars
+		return &InsufficientObservationsError{Have: observations, Need: numOfvars + 1}
+	}
+
+	for _, d := range r.Data {
+		if r.transformed && d.Observed <= 0 {
+			return ErrNonPositiveObserved
+		}
+		if d.Weight < 0 {
+			return ErrNegativeWeight
+		}
 	}
 
+	for i := range r.fixed {
+		if i < 0 || i > numOfvars {
+			return ErrInvalidIndex
+		}
+	}
+	for i := range r.bounds {
+		if i < 0 || i > numOfvars {
+			return ErrInvalidIndex
+		}
+	}
+
+	variables, observed := r.designMatrix()
+	if r.errorCov != nil {
+		variables, observed = r.whitenErrors(variables, observed)
+	}
+	if err := r.checkCondition(variables); err != nil {
+		return err
+	}
+	weights := r.dataWeights()
+	c := r.solve(variables, observed, weights)
+
+	// Output the regression results
+	r.coeff = make(map[int]float64, numOfvars)
+	for i, val := range c {
+		r.coeff[i] = val
+	}
+	r.variables, r.observed, r.weights = variables, observed, weights
+	r.numVars = len(r.Data[0].Variables)
+	r.overfit = observations-numOfvars-1 < minResidualDF
+	r.xtxInv = nil
+
+	r.Ready = true
+	r.refresh()
+	return nil
+}
+
+// checkCondition checks the condition number of the design matrix against the configured threshold.
+func (r *Regression) checkCondition(variables *mat.Dense) error {
+	if r.maxCondition == 0 {
+		return nil
+	}
+	var svd mat.SVD
+	if !svd.Factorize(variables, mat.SVDNone) {
+		return ErrSingularMatrix
+	}
+	if cond := svd.Cond(); cond > r.maxCondition {
+		return &IllConditionedError{Condition: cond, Threshold: r.maxCondition}
+	}
+	return nil
+}
+
+// designMatrix builds the matrix of variables, with a leading column of ones for the offset,
+// and the column of observations.
+func (r *Regression) designMatrix() (*mat.Dense, *mat.Dense) {
+	observations := len(r.Data)
+	numOfvars := len(r.Data[0].Variables) + len(r.Data[0].Crosses)
+
 	// Create some blank variable space
 	observed := mat.NewDense(observations, 1, nil)
 	variables := mat.NewDense(observations, numOfvars+1, nil)
 
 	for i := 0; i < observations; i++ {
-		observed.Set(i, 0, r.Data[i].Observed)
+		observed.Set(i, 0, r.response(r.Data[i].Observed))
 		variables.Set(i, 0, 1)
 		for j, val := range r.Data[i].Variables {
 			variables.Set(i, j+1, val)
 		}
 		for j, val := range r.Data[i].Crosses {
-			variables.Set(i, len(r.Data[i].Variables)+j, val)
+			variables.Set(i, len(r.Data[i].Variables)+j+1, val)
+		}
+	}
+	return variables, observed
+}
+
+// solve returns the coefficients fitted on the design matrix, honouring the weights, the fixed
+// coefficients, the bounds, the centering, the regularization, the quantile and the distribution.
+func (r *Regression) solve(variables, observed *mat.Dense, weights []float64) []float64 {
+	_, p := variables.Dims()
+	if r.family != nil {
+		return solveGLM(variables, observed, weights, r.family)
+	}
+	if len(r.bounds) > 0 {
+		return solveBounded(variables, observed, weights, r.bounds)
+	}
+	if r.quantileFit {
+		return solveQuantile(variables, observed, weights, r.tau)
+	}
+	if r.nonNegative && (p > 1 || r.nonNegativeOffset) {
+		return solveNonNegative(variables, observed, weights, r.nonNegativeOffset)
+	}
+	if r.penalty != NoRegularization && p > 1 {
+		return r.solvePenalized(variables, observed, weights, r.penaltyLambda)
+	}
+	if _, fixedOffset := r.fixed[0]; r.centering && !fixedOffset && p > 1 {
+		return solveCentered(variables, observed, weights, r.fixed)
+	}
+	return solveConstrained(variables, observed, weights, r.fixed)
+}
+
+// solveCentered fits the coefficients of the variables centered on their means, then recovers
+// the offset. The first column of variables must be the column of ones.
+func solveCentered(variables, observed *mat.Dense, weights []float64, fixed map[int]float64) []float64 {
+	centered, centeredObserved, means, obmean := center(variables, observed, weights)
+	shifted := make(map[int]float64, len(fixed))
+	for j, val := range fixed {
+		shifted[j-1] = val
+	}
+	return withOffset(solveConstrained(centered, centeredObserved, weights, shifted), means, obmean)
+}
+
+// center returns the variables, without the leading column of ones, and the observations centered on
+// their weighted means, along with these means.
+func center(variables, observed *mat.Dense, weights []float64) (centered, centeredObserved *mat.Dense, means []float64, obmean float64) {
+	n, p := variables.Dims()
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
 		}
+		return weights[i]
+	}
+	var wtotal float64
+	means = make([]float64, p-1)
+	for i := 0; i < n; i++ {
+		wtotal += weight(i)
+		obmean += weight(i) * observed.At(i, 0)
+		for j := 1; j < p; j++ {
+			means[j-1] += weight(i) * variables.At(i, j)
+		}
+	}
+	obmean /= wtotal
+	for j := range means {
+		means[j] /= wtotal
+	}
+
+	centered = mat.NewDense(n, p-1, nil)
+	centeredObserved = mat.NewDense(n, 1, nil)
+	for i := 0; i < n; i++ {
+		centeredObserved.Set(i, 0, observed.At(i, 0)-obmean)
+		for j := 1; j < p; j++ {
+			centered.Set(i, j-1, variables.At(i, j)-means[j-1])
+		}
+	}
+	return centered, centeredObserved, means, obmean
+}
+
+// withOffset prepends to coefficients fitted on centered data the offset of the uncentered model.
+func withOffset(c, means []float64, obmean float64) []float64 {
+	offset := obmean
+	for j, val := range c {
+		offset -= val * means[j]
+	}
+	return append([]float64{offset}, c...)
+}
+
+// solveConstrained returns the least squares coefficients, the fixed ones being set to their value.
+func solveConstrained(variables, observed *mat.Dense, weights []float64, fixed map[int]float64) []float64 {
+	if weights != nil {
+		variables, observed = weightRows(variables, weights), weightRows(observed, weights)
+	}
+	if len(fixed) == 0 {
+		return solveLeastSquares(variables, observed)
 	}
 
-	// Now run the regression
+	// move the fixed terms to the observed side, and fit the remaining columns
+	n, p := variables.Dims()
+	c := make([]float64, p)
+	adjusted := mat.DenseCopyOf(observed)
+	var free []int
+	for j := 0; j < p; j++ {
+		val, ok := fixed[j]
+		if !ok {
+			free = append(free, j)
+			continue
+		}
+		c[j] = val
+		for i := 0; i < n; i++ {
+			adjusted.Set(i, 0, adjusted.At(i, 0)-val*variables.At(i, j))
+		}
+	}
+	if len(free) == 0 {
+		return c
+	}
+
+	reduced := mat.NewDense(n, len(free), nil)
+	for k, j := range free {
+		for i := 0; i < n; i++ {
+			reduced.Set(i, k, variables.At(i, j))
+		}
+	}
+	for k, val := range solveLeastSquares(reduced, adjusted) {
+		c[free[k]] = val
+	}
+	return c
+}
+
+// dataWeights returns the weights of the data points, or nil if they all have the same weight.
+func (r *Regression) dataWeights() []float64 {
+	weights := make([]float64, len(r.Data))
+	uniform := true
+	for i, d := range r.Data {
+		weights[i] = d.weight()
+		uniform = uniform && weights[i] == weights[0]
+	}
+	if uniform {
+		return nil
+	}
+	return weights
+}
+
+// solveLeastSquares returns the coefficients minimising the squared residuals, using QR decomposition.
+func solveLeastSquares(variables, observed *mat.Dense) []float64 {
 	_, n := variables.Dims() // cols
 	qr := new(mat.QR)
 	qr.Factorize(variables)
@@ -143,17 +938,73 @@ func (r *Regression) Run() error {
 		}
 		c[i] /= reg.At(i, i)
 	}
+	return c
+}
 
-	// Output the regression results
-	r.coeff = make(map[int]float64, numOfvars)
-	for i, val := range c {
-		r.coeff[i] = val
+// weightRows returns a copy of m with each row scaled by the square root of its weight,
+// turning an ordinary least squares problem into a weighted one.
+func weightRows(m *mat.Dense, weights []float64) *mat.Dense {
+	w := mat.DenseCopyOf(m)
+	_, cols := w.Dims()
+	for i, wi := range weights {
+		sw := math.Sqrt(wi)
+		for j := 0; j < cols; j++ {
+			w.Set(i, j, w.At(i, j)*sw)
+		}
 	}
+	return w
+}
 
-	r.calcPredicted()
-	r.calcVariance()
-	r.calcR2()
-	return nil
+// ScoreR2 returns the coefficient of determination of the model on the given points,
+// typically held-out data which was not used for training.
+func (r *Regression) ScoreR2(points []DataPoint) (float64, error) {
+	if !r.Ready {
+		return 0, ErrRegressionRun
+	}
+	if len(points) == 0 {
+		return 0, ErrNotEnoughData
+	}
+
+	var total float64
+	for _, p := range points {
+		total += p.Observed
+	}
+	average := total / float64(len(points))
+
+	var ssRes, ssTot float64
+	for _, p := range points {
+		predicted, err := r.Predict(p.Variables)
+		if err != nil {
+			return 0, err
+		}
+		ssRes += math.Pow(p.Observed-predicted, 2)
+		ssTot += math.Pow(p.Observed-average, 2)
+	}
+	if ssTot == 0 {
+		return 0, ErrZeroVariance
+	}
+	return 1 - ssRes/ssTot, nil
+}
+
+// EvaluateLoss returns the average of the loss between the observed and the predicted values of the
+// given points, allowing to evaluate the model with any metric.
+func (r *Regression) EvaluateLoss(points []DataPoint, loss func(observed, predicted float64) float64) (float64, error) {
+	if !r.Ready {
+		return 0, ErrRegressionRun
+	}
+	if len(points) == 0 {
+		return 0, ErrNotEnoughData
+	}
+
+	var total float64
+	for _, p := range points {
+		predicted, err := r.Predict(p.Variables)
+		if err != nil {
+			return 0, err
+		}
+		total += loss(p.Observed, predicted)
+	}
+	return total / float64(len(points)), nil
 }
 
 // Coeff returns the calculated coefficient for variable i.
@@ -164,7 +1015,8 @@ func (r *Regression) Coeff(i int) float64 {
 	return r.coeff[i]
 }
 
-// GetCoeffs returns the calculated coefficients. The element at index 0 is the offset.
+// GetCoeffs returns the calculated coefficients. The element at index 0 is the offset, followed by
+// one element per variable, then one element per output of each feature cross, in registration order.
 func (r *Regression) GetCoeffs() []float64 {
 	if len(r.coeff) == 0 {
 		return nil
@@ -176,6 +1028,112 @@ func (r *Regression) GetCoeffs() []float64 {
 	return coeffs
 }
 
+// VariableMeans returns the mean of each variable over the training data points.
+func (r *Regression) VariableMeans() ([]float64, error) {
+	if len(r.Data) == 0 {
+		return nil, ErrNotEnoughData
+	}
+	means := make([]float64, len(r.Data[0].Variables))
+	for _, d := range r.Data {
+		for j, val := range d.Variables {
+			means[j] += val
+		}
+	}
+	for j := range means {
+		means[j] /= float64(len(r.Data))
+	}
+	return means, nil
+}
+
+// ObservedMean returns the mean of the observed values over the training data points.
+func (r *Regression) ObservedMean() (float64, error) {
+	if len(r.Data) == 0 {
+		return 0, ErrNotEnoughData
+	}
+	var total float64
+	for _, d := range r.Data {
+		total += d.Observed
+	}
+	return total / float64(len(r.Data)), nil
+}
+
+// Overfit reports whether the last run had so few observations compared to the number of variables
+// and feature crosses (less than 5 residual degrees of freedom) that the model may fit the noise and
+// its statistics are unreliable. This is informational, the fit is still performed.
+func (r *Regression) Overfit() bool {
+	return r.overfit
+}
+
+// leastSquares reports whether the regression is configured for an ordinary least squares fit,
+// possibly weighted, to which the closed-form statistics based on the hat matrix apply.
+func (r *Regression) leastSquares() bool {
+	return !r.nonNegative && r.penalty == NoRegularization && !r.quantileFit && r.family == nil && len(r.bounds) == 0 && r.errorCov == nil
+}
+
+// requireData checks that the regression has run on retained training data points.
+func (r *Regression) requireData() error {
+	if !r.Ready {
+		return ErrRegressionRun
+	}
+	if r.variables == nil {
+		return ErrNoTrainingData
+	}
+	return nil
+}
+
+// requireResiduals checks that the regression has run on retained training data points, with a fit
+// whose residuals are the differences between the observed values and the linear predictor, which is
+// not the case for distributions.
+func (r *Regression) requireResiduals() error {
+	if err := r.requireData(); err != nil {
+		return err
+	}
+	if r.family != nil {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// DesignMatrix returns a copy of the matrix used by the last run: a column of ones for the offset,
+// followed by the variables and the feature crosses, with one row per data point.
+func (r *Regression) DesignMatrix() (*mat.Dense, error) {
+	if err := r.requireData(); err != nil {
+		return nil, err
+	}
+	return mat.DenseCopyOf(r.variables), nil
+}
+
+// TrainingResults returns a copy of the training data points, with their Predicted and Error fields
+// populated by the last run, e.g. to export fitted values and residuals.
+func (r *Regression) TrainingResults() ([]DataPoint, error) {
+	if err := r.requireData(); err != nil {
+		return nil, err
+	}
+	results := make([]DataPoint, len(r.Data))
+	for i, d := range r.Data {
+		d.Variables = append([]float64(nil), d.Variables...)
+		d.Crosses = append([]float64(nil), d.Crosses...)
+		results[i] = d
+	}
+	return results, nil
+}
+
+// CoeffMap returns the coefficients keyed by the names of the variables and the feature crosses,
+// the offset being keyed "(Intercept)". Variables without a name set with SetVar are named x0, x1...
+func (r *Regression) CoeffMap() (map[string]float64, error) {
+	if !r.Ready {
+		return nil, ErrRegressionRun
+	}
+	if len(r.varNames) == 0 {
+		return nil, ErrNoVariableNames
+	}
+	coeffs := map[string]float64{"(Intercept)": r.Coeff(0)}
+	for j, name := range r.featureNames() {
+		coeffs[name] = r.Coeff(j + 1)
+	}
+	return coeffs, nil
+}
+
 func (r *Regression) calcPredicted() {
 	observations := len(r.Data)
 	for i := 0; i < observations; i++ {
@@ -202,8 +1160,44 @@ func (r *Regression) calcVariance() {
 	r.VariancePredicted = prvar / float64(observations)
 }
 
+// calcR2 computes R^2 as 1 - SSres/SStot, which unlike the ratio of the predicted and observed
+// variances remains valid when the fit is not an ordinary least squares fit of the observed values.
 func (r *Regression) calcR2() {
-	r.R2 = r.VariancePredicted / r.VarianceObserved
+	var ssRes float64
+	for _, d := range r.Data {
+		ssRes += d.Error * d.Error
+	}
+	r.R2 = 1 - ssRes/(r.VarianceObserved*float64(len(r.Data)))
+	r.AdjustedR2 = adjustR2(r.R2, len(r.Data), len(r.coeff)-1)
+
+	var wtotal, wobtotal, wssRes, wssTot float64
+	for _, d := range r.Data {
+		wtotal += d.weight()
+		wobtotal += d.weight() * d.Observed
+		wssRes += d.weight() * d.Error * d.Error
+	}
+	wobaverage := wobtotal / wtotal
+	for _, d := range r.Data {
+		wssTot += d.weight() * math.Pow(d.Observed-wobaverage, 2)
+	}
+	r.weightedR2 = 1 - wssRes/wssTot
+}
+
+// adjustR2 returns the adjusted R2 of a fit of p features on n observations, NaN when n <= p+1.
+func adjustR2(r2 float64, n, p int) float64 {
+	if n <= p+1 {
+		return math.NaN()
+	}
+	return 1 - (1-r2)*float64(n-1)/float64(n-p-1)
+}
+
+// WeightedR2 returns the coefficient of determination where both the residual and the total sums of
+// squares are weighted by the data point weights. It equals R2 when no weights are set.
+func (r *Regression) WeightedR2() (float64, error) {
+	if !r.Ready {
+		return 0, ErrRegressionRun
+	}
+	return r.weightedR2, nil
 }
 
 // MakeDataPoints makes a `[]DataPoint` from a `[][]float64`. The expected fomat for the input is a row-major [][]float64.
@@ -231,6 +1225,80 @@ func MakeDataPoints(a [][]float64, obsIndex int) []DataPoint {
 	return retVal
 }
 
+// MakeDataPointsByName is like MakeDataPoints, but selects the observation column by its name in the
+// header. It also returns the names of the variables, e.g. to pass to SetVar.
+func MakeDataPointsByName(a [][]float64, header []string, obsName string) ([]DataPoint, []string, error) {
+	obsIndex := -1
+	for i, name := range header {
+		if name == obsName {
+			obsIndex = i
+			break
+		}
+	}
+	if obsIndex < 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownColumn, obsName)
+	}
+	for _, row := range a {
+		if len(row) != len(header) {
+			return nil, nil, ErrVariableCount
+		}
+	}
+
+	names := make([]string, 0, len(header)-1)
+	names = append(names, header[:obsIndex]...)
+	names = append(names, header[obsIndex+1:]...)
+	if len(a) == 0 {
+		return nil, names, nil
+	}
+	return MakeDataPoints(a, obsIndex), names, nil
+}
+
+// MakeDataPointsFromColumns is like MakeDataPointsByName, but reads column-oriented data, such as the
+// data frames of gota or dataframe-go, without depending on them: column returns the values of the
+// column with the given name, and all the columns but the observation one are variables. With gota:
+//
+//	data, names, err := regression.MakeDataPointsFromColumns(df.Names(), func(name string) []float64 {
+//		return df.Col(name).Float()
+//	}, "price")
+//
+// It fails with ErrObservationCount if the columns have different lengths.
+func MakeDataPointsFromColumns(names []string, column func(name string) []float64, obsName string) ([]DataPoint, []string, error) {
+	obsIndex := -1
+	for i, name := range names {
+		if name == obsName {
+			obsIndex = i
+			break
+		}
+	}
+	if obsIndex < 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownColumn, obsName)
+	}
+
+	observed := column(obsName)
+	vars := make([]string, 0, len(names)-1)
+	var columns [][]float64
+	for i, name := range names {
+		if i == obsIndex {
+			continue
+		}
+		values := column(name)
+		if len(values) != len(observed) {
+			return nil, nil, ErrObservationCount
+		}
+		vars = append(vars, name)
+		columns = append(columns, values)
+	}
+
+	data := make([]DataPoint, len(observed))
+	for k, obs := range observed {
+		data[k] = DataPoint{Observed: obs, Variables: make([]float64, len(columns))}
+		for j, values := range columns {
+			data[k].Variables[j] = values[k]
+		}
+	}
+	return data, vars, nil
+}
+
 func perverseMakeDataPoints(a [][]float64, obsIndex int) []DataPoint {
 	retVal := make([]DataPoint, 0, len(a))
 	for _, r := range a {
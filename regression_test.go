@@ -1,7 +1,9 @@
 package regression
 
 import (
+	"errors"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -172,3 +174,1000 @@ func TestGetCoeffs(t *testing.T) {
 		}
 	}
 }
+
+func TestPredictLocal(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i <= 40; i++ {
+		x := float64(i) * 2 * math.Pi / 40
+		r.Train(DataPoint{Observed: math.Sin(x), Variables: []float64{x}})
+	}
+	if _, err := r.PredictLocal([]float64{1}, 0.5); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.PredictLocal([]float64{1}, 0); err != ErrInvalidBandwidth {
+		t.Errorf("Expected %v, got %v", ErrInvalidBandwidth, err)
+	}
+	if _, err := r.PredictLocal([]float64{1, 2}, 0.5); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+
+	var globalErr, localErr float64
+	for x := 0.5; x < 6; x += 0.5 {
+		global, _ := r.Predict([]float64{x})
+		local, err := r.PredictLocal([]float64{x}, 0.3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		globalErr += math.Pow(global-math.Sin(x), 2)
+		localErr += math.Pow(local-math.Sin(x), 2)
+	}
+	if localErr > globalErr/10 {
+		t.Errorf("Expected the local fit to track the curve, got squared errors %.4f (local) vs %.4f (global)", localErr, globalErr)
+	}
+
+	// the refit would drop the configuration of the fit
+	for _, configure := range []func(r *Regression){
+		func(r *Regression) { r.SetRegularization(L2, 0.1) },
+		func(r *Regression) { r.SetQuantile(0.5) },
+		func(r *Regression) { r.SetNonNegative(true) },
+		func(r *Regression) { r.SetBound(1, -10, 10) },
+		func(r *Regression) { r.FixCoeff(0, 0) },
+		func(r *Regression) { r.SetFamily(Gaussian) },
+	} {
+		configured := &Regression{}
+		configured.Train(r.Data...)
+		configure(configured)
+		if err := configured.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := configured.PredictLocal([]float64{1}, 0.5); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+		}
+	}
+}
+
+func TestScoreR2(t *testing.T) {
+	var points []DataPoint
+	for i := 0; i < 40; i++ {
+		x := float64(i)
+		// deterministic noise
+		points = append(points, DataPoint{Observed: 3*x + 2 + 4*math.Sin(7*x), Variables: []float64{x}})
+	}
+	train, test := points[:30], points[30:]
+
+	r := &Regression{}
+	if _, err := r.ScoreR2(test); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(train...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	inSample, err := r.ScoreR2(train)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(inSample-r.R2) > 1e-9 {
+		t.Errorf("Expected in-sample score to match R^2 %.4f, got %.4f", r.R2, inSample)
+	}
+
+	outOfSample, err := r.ScoreR2(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outOfSample >= inSample {
+		t.Errorf("Expected out-of-sample R^2 %.4f to be lower than in-sample %.4f", outOfSample, inSample)
+	}
+
+	constant := []DataPoint{
+		{Observed: 1, Variables: []float64{1}},
+		{Observed: 1, Variables: []float64{2}},
+	}
+	if _, err := r.ScoreR2(constant); err != ErrZeroVariance {
+		t.Errorf("Expected %v, got %v", ErrZeroVariance, err)
+	}
+}
+
+func TestAddAllInteractions(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 20; i++ {
+		x, y, z := float64(i), math.Sin(float64(i)), math.Cos(float64(i))
+		r.Train(DataPoint{Observed: 1 + x + 2*y*z, Variables: []float64{x, y, z}})
+	}
+	r.AddAllInteractions(3)
+	r.AddAllInteractions(3)
+	if len(r.crosses) != 3 {
+		t.Fatalf("Expected 3 interaction crosses, got %d", len(r.crosses))
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if coeffs := r.GetCoeffs(); len(coeffs) != 7 {
+		t.Errorf("Expected 7 coefficients, got %d", len(coeffs))
+	}
+}
+
+func TestExplain(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.Explain([]float64{1, 2}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 3 + 2*x - y + 0.5*x*y, Variables: []float64{x, y}})
+	}
+	r.AddCross(MultiplierCross(0, 1))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := []float64{4.5, 0.3}
+	contributions, err := r.Explain(vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contributions) != 4 {
+		t.Fatalf("Expected 4 contributions, got %d", len(contributions))
+	}
+	var sum float64
+	for _, c := range contributions {
+		sum += c
+	}
+	predicted, _ := r.Predict(vars)
+	if sum != predicted {
+		t.Errorf("Expected contributions to sum to %v, got %v", predicted, sum)
+	}
+}
+
+func TestTransformResponse(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 20; i++ {
+		x := float64(i) / 2
+		data = append(data, DataPoint{Observed: 2 * math.Exp(0.8*x) * (1 + 0.05*math.Sin(float64(i))), Variables: []float64{x}})
+	}
+
+	linear := &Regression{}
+	linear.Train(data...)
+	if err := linear.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := &Regression{}
+	logged.Train(data...)
+	logged.TransformResponse(0)
+	if err := logged.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if logged.R2 < 0.99 || logged.R2 <= linear.R2+0.1 {
+		t.Errorf("Expected the transformed R^2 %.4f to be much higher than %.4f", logged.R2, linear.R2)
+	}
+	if math.Abs(logged.Coeff(1)-0.8) > 0.01 {
+		t.Errorf("Expected a slope of 0.8 on the log scale, got %.4f", logged.Coeff(1))
+	}
+	val, err := logged.Predict([]float64{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 2 * math.Exp(2.4); math.Abs(val-expected)/expected > 0.05 {
+		t.Errorf("Expected a prediction close to %.2f on the original scale, got %.2f", expected, val)
+	}
+
+	r := &Regression{}
+	r.Train(data...)
+	r.Train(DataPoint{Observed: 0, Variables: []float64{1}})
+	r.TransformResponse(0.5)
+	if err := r.Run(); err != ErrNonPositiveObserved {
+		t.Errorf("Expected %v, got %v", ErrNonPositiveObserved, err)
+	}
+}
+
+func TestInsufficientObservations(t *testing.T) {
+	r := &Regression{}
+	r.Train(
+		DataPoint{Observed: 1, Variables: []float64{1, 2}},
+		DataPoint{Observed: 2, Variables: []float64{2, 1}},
+		DataPoint{Observed: 3, Variables: []float64{3, 5}},
+	)
+	r.AddCross(MultiplierCross(0, 1))
+	err := r.Run()
+	if !errors.Is(err, ErrTooManyVars) {
+		t.Fatalf("Expected %v, got %v", ErrTooManyVars, err)
+	}
+	var insufficient *InsufficientObservationsError
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("Expected an InsufficientObservationsError, got %T", err)
+	}
+	if insufficient.Have != 3 || insufficient.Need != 4 {
+		t.Errorf("Expected to have 3 and need 4 observations, got %d and %d", insufficient.Have, insufficient.Need)
+	}
+	if r.Ready {
+		t.Error("Expected the regression not to be ready after a failed run")
+	}
+}
+
+func TestMaxConditionNumber(t *testing.T) {
+	r := &Regression{}
+	for i := 1; i <= 10; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 2 + x, Variables: []float64{x, 1000 * x * x}})
+	}
+	r.AddCross(PowCross(0, 3))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	cond, _ := r.ConditionNumber()
+
+	r.SetMaxConditionNumber(cond / 2)
+	err := r.Run()
+	if !errors.Is(err, ErrIllConditioned) {
+		t.Fatalf("Expected %v, got %v", ErrIllConditioned, err)
+	}
+	var ill *IllConditionedError
+	if !errors.As(err, &ill) {
+		t.Fatalf("Expected an IllConditionedError, got %T", err)
+	}
+	if math.Abs(ill.Condition-cond) > 1e-6*cond || ill.Threshold != cond/2 {
+		t.Errorf("Expected a condition number of %g above %g, got %g above %g", cond, cond/2, ill.Condition, ill.Threshold)
+	}
+
+	r.SetMaxConditionNumber(cond * 2)
+	if err := r.Run(); err != nil {
+		t.Errorf("Expected the run to succeed below the threshold, got %v", err)
+	}
+}
+
+func TestDesignMatrix(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.DesignMatrix(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(
+		DataPoint{Observed: 1, Variables: []float64{1}},
+		DataPoint{Observed: 4, Variables: []float64{2}},
+		DataPoint{Observed: 9, Variables: []float64{3}},
+		DataPoint{Observed: 17, Variables: []float64{4}},
+	)
+	r.AddCross(PowCross(0, 2))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := r.DesignMatrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]float64{{1, 1, 1}, {1, 2, 4}, {1, 3, 9}, {1, 4, 16}}
+	for i, row := range expected {
+		for j, val := range row {
+			if m.At(i, j) != val {
+				t.Errorf("Expected %v at (%d, %d), got %v", val, i, j, m.At(i, j))
+			}
+		}
+	}
+
+	m.Set(0, 0, 42)
+	if m2, _ := r.DesignMatrix(); m2.At(0, 0) != 1 {
+		t.Error("Expected the design matrix to be a copy")
+	}
+}
+
+func TestPredictRaw(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Cos(float64(i))
+		r.Train(DataPoint{Observed: 1 + x + 3*y + x*x*y, Variables: []float64{x, y}})
+	}
+	r.AddCross(PowCross(0, 2))
+	r.AddCross(MultiplierCross(0, 1))
+	if _, err := r.PredictRaw([]float64{1, 2, 1, 2}); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	x, y := 2.5, -0.7
+	expected, _ := r.Predict([]float64{x, y})
+	val, err := r.PredictRaw([]float64{x, y, x * x, x * y})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != expected {
+		t.Errorf("Expected %v, got %v", expected, val)
+	}
+
+	if _, err := r.PredictRaw([]float64{x, y}); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+}
+
+// expensiveCross simulates a costly feature cross.
+type expensiveCross int
+
+func (c expensiveCross) Calculate(vars []float64) []float64 {
+	v := vars[c]
+	for k := 0; k < 100; k++ {
+		v = math.Sqrt(v*v + 1)
+	}
+	return []float64{v}
+}
+
+func benchmarkRegression(b *testing.B) *Regression {
+	r := &Regression{}
+	for i := 0; i < 50; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: x + y, Variables: []float64{x, y}})
+	}
+	r.AddCross(expensiveCross(0))
+	r.AddCross(expensiveCross(1))
+	if err := r.Run(); err != nil {
+		b.Fatal(err)
+	}
+	return r
+}
+
+func BenchmarkPredict(b *testing.B) {
+	r := benchmarkRegression(b)
+	vars := []float64{3, 0.5}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = r.Predict(vars)
+	}
+}
+
+func BenchmarkPredictRaw(b *testing.B) {
+	r := benchmarkRegression(b)
+	vars := []float64{3, 0.5}
+	expanded := append(vars, r.crosses[0].Calculate(vars)[0], r.crosses[1].Calculate(vars)[0])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = r.PredictRaw(expanded)
+	}
+}
+
+func TestWeightedR2(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.WeightedR2(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 30; i++ {
+		x := float64(i)
+		// the second half is noisy, and trusted less
+		d := DataPoint{Observed: 2*x + 1, Variables: []float64{x}}
+		if i >= 15 {
+			d.Observed += 10 * math.Sin(3*x)
+			d.Weight = 0.1
+		}
+		r.Train(d)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	weighted, err := r.WeightedR2()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weighted <= r.R2 {
+		t.Errorf("Expected the weighted R^2 %.4f to be above the unweighted one %.4f", weighted, r.R2)
+	}
+	if math.Abs(r.Coeff(1)-2) > 0.2 {
+		t.Errorf("Expected the weighted fit to follow the trusted points, got slope %.4f", r.Coeff(1))
+	}
+
+	u := &Regression{}
+	u.Train(MakeDataPoints([][]float64{{1, 1}, {3, 2}, {2, 3}, {5, 4}}, 0)...)
+	if err := u.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if weighted, _ := u.WeightedR2(); math.Abs(weighted-u.R2) > 1e-12 {
+		t.Errorf("Expected the weighted R^2 %.4f to equal R^2 %.4f without weights", weighted, u.R2)
+	}
+}
+
+func TestPredictParallel(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.PredictParallel([][]float64{{1}}, 2); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: x*x + math.Sin(x), Variables: []float64{x}})
+	}
+	r.AddCross(PowCross(0, 2))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := make([][]float64, 1000)
+	for i := range rows {
+		rows[i] = []float64{float64(i) / 10}
+	}
+	serial, err := r.PredictBatch(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parallel, err := r.PredictParallel(rows, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		expected, _ := r.Predict(rows[i])
+		if serial[i] != expected || parallel[i] != expected {
+			t.Fatalf("Expected %v for row %d, got %v (batch) and %v (parallel)", expected, i, serial[i], parallel[i])
+		}
+	}
+
+	if _, err := r.PredictParallel([][]float64{{1}, {1, 2}}, 2); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+}
+
+func benchmarkRows() [][]float64 {
+	rows := make([][]float64, 100000)
+	for i := range rows {
+		rows[i] = []float64{float64(i), math.Sin(float64(i))}
+	}
+	return rows
+}
+
+func BenchmarkPredictBatch(b *testing.B) {
+	r := benchmarkRegression(b)
+	rows := benchmarkRows()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = r.PredictBatch(rows)
+	}
+}
+
+func BenchmarkPredictParallel(b *testing.B) {
+	r := benchmarkRegression(b)
+	rows := benchmarkRows()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = r.PredictParallel(rows, 0)
+	}
+}
+
+func TestFixCoeff(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 20; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		data = append(data, DataPoint{Observed: 4 + 2*x + 3*y, Variables: []float64{x, y}})
+	}
+
+	r := &Regression{}
+	r.Train(data...)
+	r.FixCoeff(1, 2)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{4, 2, 3}
+	for i, c := range r.GetCoeffs() {
+		if math.Abs(c-expected[i]) > 1e-9 {
+			t.Errorf("Expected coefficients %v, got %v", expected, r.GetCoeffs())
+		}
+	}
+
+	// a wrong a priori slope shifts the offset to compensate
+	r.FixCoeff(1, 1)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Coeff(1) != 1 || r.Coeff(0) < 10 {
+		t.Errorf("Expected the offset to adjust to the fixed slope, got %v", r.GetCoeffs())
+	}
+
+	r.FixCoeff(5, 1)
+	if err := r.Run(); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestTrainingResults(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.TrainingResults(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(MakeDataPoints([][]float64{{1, 1}, {3, 2}, {2, 3}, {5, 4}}, 0)...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := r.TrainingResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range results {
+		expected, _ := r.Predict(p.Variables)
+		if p.Predicted != expected || p.Error != expected-p.Observed {
+			t.Errorf("Expected point %d to be predicted %v with error %v, got %v and %v", i, expected, expected-p.Observed, p.Predicted, p.Error)
+		}
+	}
+
+	results[0].Variables[0] = 42
+	if r.Data[0].Variables[0] == 42 {
+		t.Error("Expected the training results to be a copy")
+	}
+}
+
+func TestCentering(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 50; i++ {
+		x, y := 1e6+float64(i), 1e5+float64(i*i%7)
+		data = append(data, DataPoint{Observed: 1e9 + 3*x - 2*y, Variables: []float64{x, y}})
+	}
+	expected := []float64{1e9, 3, -2}
+
+	plain := &Regression{}
+	plain.Train(data...)
+	if err := plain.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	centered := &Regression{}
+	centered.Train(data...)
+	centered.SetCentering(true)
+	if err := centered.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var plainErr, centeredErr float64
+	for i, c := range expected {
+		plainErr += math.Abs(plain.Coeff(i)-c) / math.Abs(c)
+		centeredErr += math.Abs(centered.Coeff(i)-c) / math.Abs(c)
+	}
+	if centeredErr > 1e-6 || centeredErr > plainErr+1e-12 {
+		t.Errorf("Expected the centered fit to be accurate, got relative errors %g (centered) vs %g (plain)", centeredErr, plainErr)
+	}
+
+	vars := []float64{1e6 + 3.5, 1e5 + 2}
+	p1, _ := plain.Predict(vars)
+	p2, _ := centered.Predict(vars)
+	if math.Abs(p1-p2)/math.Abs(p1) > 1e-6 {
+		t.Errorf("Expected matching predictions, got %v and %v", p1, p2)
+	}
+}
+
+func TestEvaluateLoss(t *testing.T) {
+	absolute := func(observed, predicted float64) float64 {
+		return math.Abs(observed - predicted)
+	}
+	r := &Regression{}
+	if _, err := r.EvaluateLoss(nil, absolute); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(
+		DataPoint{Observed: 1, Variables: []float64{0}},
+		DataPoint{Observed: 3, Variables: []float64{1}},
+		DataPoint{Observed: 5, Variables: []float64{2}},
+	)
+	r.AddCross(PowCross(0, 2))
+	r.FixCoeff(2, 0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	loss, err := r.EvaluateLoss([]DataPoint{
+		{Observed: 8, Variables: []float64{3}},
+		{Observed: 8, Variables: []float64{4}},
+	}, absolute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(loss-1) > 1e-9 {
+		t.Errorf("Expected a mean absolute error of 1, got %v", loss)
+	}
+}
+
+func TestMeans(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.VariableMeans(); err != ErrNotEnoughData {
+		t.Errorf("Expected %v, got %v", ErrNotEnoughData, err)
+	}
+	if _, err := r.ObservedMean(); err != ErrNotEnoughData {
+		t.Errorf("Expected %v, got %v", ErrNotEnoughData, err)
+	}
+	r.Train(MakeDataPoints([][]float64{{1, 2, 10}, {2, 4, 20}, {6, 9, 60}}, 0)...)
+
+	means, err := r.VariableMeans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(means) != 2 || means[0] != 5 || means[1] != 30 {
+		t.Errorf("Expected variable means [5 30], got %v", means)
+	}
+	if mean, _ := r.ObservedMean(); mean != 3 {
+		t.Errorf("Expected observed mean 3, got %v", mean)
+	}
+}
+
+func TestCoeffMap(t *testing.T) {
+	r := &Regression{}
+	r.Train(MakeDataPoints([][]float64{
+		{651, 1, 23},
+		{762, 2, 26},
+		{856, 3, 30},
+		{1063, 4, 34},
+		{1190, 5, 43},
+		{1298, 6, 48},
+	}, 0)...)
+	r.AddCross(PowCross(0, 2))
+	if _, err := r.CoeffMap(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CoeffMap(); err != ErrNoVariableNames {
+		t.Errorf("Expected %v, got %v", ErrNoVariableNames, err)
+	}
+
+	r.SetVar(0, "Year")
+	r.SetVar(1, "Staff")
+	coeffs, err := r.CoeffMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]float64{
+		"(Intercept)": r.Coeff(0),
+		"Year":        r.Coeff(1),
+		"Staff":       r.Coeff(2),
+		"Year^2":      r.Coeff(3),
+	}
+	if len(coeffs) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, coeffs)
+	}
+	for name, c := range expected {
+		if coeffs[name] != c {
+			t.Errorf("Expected coefficient %s to be %v, got %v", name, c, coeffs[name])
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	r := &Regression{}
+	if r.GetObserved() != "y" || r.GetVar(1) != "x1" {
+		t.Errorf("Expected default names y and x1, got %s and %s", r.GetObserved(), r.GetVar(1))
+	}
+	r.SetObserved("Murders")
+	r.SetVar(1, "Poverty")
+	if r.GetObserved() != "Murders" || r.GetVar(1) != "Poverty" || r.GetVar(0) != "x0" {
+		t.Errorf("Expected names Murders, Poverty and x0, got %s, %s and %s", r.GetObserved(), r.GetVar(1), r.GetVar(0))
+	}
+}
+
+func TestString(t *testing.T) {
+	r := &Regression{}
+	if s := r.String(); s != ErrRegressionRun.Error() {
+		t.Errorf("Expected %q, got %q", ErrRegressionRun.Error(), s)
+	}
+	for i := 0; i < 10; i++ {
+		x, z := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 3.21 + 0.0001*x - 1.2*z + 0.5*x*x, Variables: []float64{x, z}})
+	}
+	r.AddCross(PowCross(0, 2))
+	r.SetObserved("Murders")
+	r.SetVar(0, "Population")
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Murders = 3.21 + 0.0001*Population - 1.2*x1 + 0.5*Population^2"; r.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, r.String())
+	}
+
+	r.TransformResponse(0)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.String(); !strings.HasPrefix(s, "log(Murders) = ") {
+		t.Errorf("Expected the transformed response on the left-hand side, got %q", s)
+	}
+}
+
+func TestMakeDataPointsByName(t *testing.T) {
+	a := [][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+	}
+	header := []string{"age", "income", "rooms"}
+
+	dps, names, err := MakeDataPointsByName(a, header, "income")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "age" || names[1] != "rooms" {
+		t.Errorf("Expected names [age rooms], got %v", names)
+	}
+	for i, dp := range dps {
+		if dp.Observed != a[i][1] || dp.Variables[0] != a[i][0] || dp.Variables[1] != a[i][2] {
+			t.Errorf("Expected observed %v and variables %v, got %v and %v", a[i][1], []float64{a[i][0], a[i][2]}, dp.Observed, dp.Variables)
+		}
+	}
+
+	if _, _, err := MakeDataPointsByName(a, header, "price"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected %v, got %v", ErrUnknownColumn, err)
+	}
+}
+
+func TestMakeDataPointsFromColumns(t *testing.T) {
+	frame := map[string][]float64{
+		"age":    {1, 4},
+		"income": {2, 5},
+		"rooms":  {3, 6},
+	}
+	column := func(name string) []float64 { return frame[name] }
+
+	dps, names, err := MakeDataPointsFromColumns([]string{"rooms", "income", "age"}, column, "income")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "rooms" || names[1] != "age" {
+		t.Errorf("Expected names [rooms age], got %v", names)
+	}
+	for i, dp := range dps {
+		if dp.Observed != frame["income"][i] || dp.Variables[0] != frame["rooms"][i] || dp.Variables[1] != frame["age"][i] {
+			t.Errorf("Expected observed %v and variables %v, got %v and %v", frame["income"][i], []float64{frame["rooms"][i], frame["age"][i]}, dp.Observed, dp.Variables)
+		}
+	}
+
+	if _, _, err := MakeDataPointsFromColumns([]string{"age", "rooms"}, column, "price"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected %v, got %v", ErrUnknownColumn, err)
+	}
+	frame["age"] = frame["age"][:1]
+	if _, _, err := MakeDataPointsFromColumns([]string{"age", "rooms"}, column, "rooms"); err != ErrObservationCount {
+		t.Errorf("Expected %v, got %v", ErrObservationCount, err)
+	}
+}
+
+func TestOverfit(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 8; i++ {
+		x := float64(i)
+		data = append(data, DataPoint{Observed: x + math.Sin(x), Variables: []float64{x}})
+	}
+
+	r := &Regression{}
+	r.Train(data...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Overfit() {
+		t.Error("Expected 6 residual degrees of freedom not to be flagged")
+	}
+
+	r = &Regression{}
+	r.Train(data...)
+	r.AddCross(PowCross(0, 2))
+	r.AddCross(PowCross(0, 3))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Overfit() {
+		t.Error("Expected 4 residual degrees of freedom to be flagged")
+	}
+}
+
+// pairCross returns two outputs, the square and the cube of a variable.
+type pairCross int
+
+func (c pairCross) Calculate(vars []float64) []float64 {
+	x := vars[c]
+	return []float64{x * x, x * x * x}
+}
+
+func TestMultipleCrosses(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 15; i++ {
+		x, y := float64(i)/3, math.Cos(float64(i))
+		r.Train(DataPoint{Observed: 1 + 2*x*x - x*x*x + 3*x*y, Variables: []float64{x, y}})
+	}
+	r.AddCross(pairCross(0))
+	r.AddCross(MultiplierCross(0, 1))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range r.Data {
+		if len(d.Crosses) != 3 {
+			t.Fatalf("Expected 3 cross outputs per data point, got %v", d.Crosses)
+		}
+	}
+	// offset, x, y, x^2, x^3, x*y
+	expected := []float64{1, 0, 0, 2, -1, 3}
+	coeffs := r.GetCoeffs()
+	if len(coeffs) != len(expected) {
+		t.Fatalf("Expected %d coefficients, got %v", len(expected), coeffs)
+	}
+	for i, c := range expected {
+		if math.Abs(coeffs[i]-c) > 1e-6 {
+			t.Errorf("Expected coefficients %v, got %v", expected, coeffs)
+			break
+		}
+	}
+	if math.Abs(r.R2-1) > 1e-9 {
+		t.Errorf("Expected a perfect fit, got R^2 %v", r.R2)
+	}
+}
+
+func TestCrossAddedAfterRun(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 15; i++ {
+		x := float64(i) / 3
+		r.Train(DataPoint{Observed: 1 + x + 2*x*x - x*x*x, Variables: []float64{x}})
+	}
+	r.AddCross(PowCross(0, 2))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	r.AddCross(PowCross(0, 3))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float64{1, 1, 2, -1}
+	coeffs := r.GetCoeffs()
+	if len(coeffs) != len(expected) {
+		t.Fatalf("Expected %d coefficients, got %v", len(expected), coeffs)
+	}
+	for i, c := range expected {
+		if math.Abs(coeffs[i]-c) > 1e-6 {
+			t.Errorf("Expected coefficients %v, got %v", expected, coeffs)
+			break
+		}
+	}
+
+	// data points carrying the crosses of another regression get those of this one
+	linear := &Regression{}
+	linear.Train(r.Data...)
+	if err := linear.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if coeffs := linear.GetCoeffs(); len(coeffs) != 2 {
+		t.Errorf("Expected 2 coefficients without crosses, got %v", coeffs)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		data = append(data, DataPoint{Observed: 1 + x + 0.2*x*x + math.Sin(x), Variables: []float64{x}})
+	}
+
+	r1, r2 := &Regression{}, &Regression{}
+	r1.AddCross(PowCross(0, 2))
+	r2.AddCross(PowCross(0, 2))
+	r1.Train(data[:10]...)
+	r2.Train(data[10:]...)
+	if err := r1.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r1.Merge(r2); err != nil {
+		t.Fatal(err)
+	}
+	if r1.Ready {
+		t.Error("Expected the merged regression to need a run")
+	}
+	if err := r1.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	all := &Regression{}
+	all.AddCross(PowCross(0, 2))
+	all.Train(data...)
+	if err := all.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range all.GetCoeffs() {
+		if math.Abs(r1.Coeff(i)-c) > 1e-9 {
+			t.Errorf("Expected coefficients %v, got %v", all.GetCoeffs(), r1.GetCoeffs())
+		}
+	}
+
+	r3 := &Regression{}
+	r3.AddCross(PowCross(0, 3))
+	r3.Train(data...)
+	if err := r1.Merge(r3); err != ErrMismatchedCrosses {
+		t.Errorf("Expected %v, got %v", ErrMismatchedCrosses, err)
+	}
+}
+
+func TestMarginalEffectAtMean(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.MarginalEffectAtMean(0); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	for i := 0; i < 20; i++ {
+		x, z := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 1 + 2*x - 0.3*x*x + 4*z, Variables: []float64{x, z}})
+	}
+	r.AddCross(PowCross(0, 2))
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// d/dx (1 + 2x - 0.3x² + 4z) = 2 - 0.6x, at the mean of x, 9.5
+	effect, err := r.MarginalEffectAtMean(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 2 - 0.6*9.5; math.Abs(effect-expected) > 1e-6 {
+		t.Errorf("Expected marginal effect %v, got %v", expected, effect)
+	}
+	if effect, _ := r.MarginalEffectAtMean(1); math.Abs(effect-4) > 1e-6 {
+		t.Errorf("Expected marginal effect 4, got %v", effect)
+	}
+	if _, err := r.MarginalEffectAtMean(2); err != ErrInvalidIndex {
+		t.Errorf("Expected %v, got %v", ErrInvalidIndex, err)
+	}
+}
+
+func TestWeightsMatchDuplicatedRows(t *testing.T) {
+	weighted, duplicated := &Regression{}, &Regression{}
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		d := DataPoint{Observed: 1 + 2*x + math.Sin(x), Variables: []float64{x}, Weight: float64(1 + i%3)}
+		weighted.Train(d)
+		for k := 0; k < int(d.Weight); k++ {
+			duplicated.Train(DataPoint{Observed: d.Observed, Variables: d.Variables})
+		}
+	}
+	if err := weighted.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := duplicated.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := duplicated.GetCoeffs()
+	for i, c := range weighted.GetCoeffs() {
+		if math.Abs(c-expected[i]) > 1e-9 {
+			t.Errorf("Expected the coefficients of the duplicated rows %v, got %v", expected, weighted.GetCoeffs())
+			break
+		}
+	}
+
+	weighted.Data[3].Weight = -1
+	if err := weighted.Run(); err != ErrNegativeWeight {
+		t.Errorf("Expected %v, got %v", ErrNegativeWeight, err)
+	}
+	if err := DataPoints(weighted.Data).Validate(); err != ErrNegativeWeight {
+		t.Errorf("Expected %v, got %v", ErrNegativeWeight, err)
+	}
+}
+
+func TestAdjustedR2(t *testing.T) {
+	r := &Regression{}
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 1 + 2*x + 3*math.Sin(x), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := r.Diagnostics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.AdjustedR2-d.AdjustedR2) > 1e-12 || r.AdjustedR2 >= r.R2 {
+		t.Errorf("Expected the adjusted R2 %v, below %v, got %v", d.AdjustedR2, r.R2, r.AdjustedR2)
+	}
+
+	// a noise variable raises R2 but hardly the adjusted R2
+	noisy := &Regression{}
+	for i, d := range r.Data {
+		noisy.Train(DataPoint{Observed: d.Observed, Variables: []float64{d.Variables[0], math.Cos(float64(7 * i))}})
+	}
+	if err := noisy.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if noisy.R2 <= r.R2 || noisy.AdjustedR2-r.AdjustedR2 > (noisy.R2-r.R2)/2 {
+		t.Errorf("Expected the noise variable to raise R2 %v more than the adjusted R2 %v, got %v and %v", r.R2, r.AdjustedR2, noisy.R2, noisy.AdjustedR2)
+	}
+}
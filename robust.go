@@ -0,0 +1,164 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Settings of the Weiszfeld algorithm computing spatial medians.
+const (
+	maxWeiszfeldIterations = 1000
+	weiszfeldTolerance     = 1e-10
+)
+
+// TheilSen estimates a simple linear regression using the Theil-Sen method: the slope is the median
+// of the slopes between all pairs of points, and the intercept the median of the observations minus
+// the slope times the variable. It is robust to up to ~29% of outliers.
+// All the data points must have exactly one variable.
+func TheilSen(data []DataPoint) (slope, intercept float64, err error) {
+	for _, d := range data {
+		if len(d.Variables) != 1 {
+			return 0, 0, ErrNotSingleVariable
+		}
+	}
+
+	slopes := make([]float64, 0, len(data)*(len(data)-1)/2)
+	for i := range data {
+		for j := i + 1; j < len(data); j++ {
+			dx := data[j].Variables[0] - data[i].Variables[0]
+			if dx == 0 {
+				continue
+			}
+			slopes = append(slopes, (data[j].Observed-data[i].Observed)/dx)
+		}
+	}
+	if len(slopes) == 0 {
+		return 0, 0, ErrNotEnoughData
+	}
+	slope = median(slopes)
+
+	intercepts := make([]float64, len(data))
+	for i, d := range data {
+		intercepts[i] = d.Observed - slope*d.Variables[0]
+	}
+	return slope, median(intercepts), nil
+}
+
+// TheilSenMultivariate generalizes TheilSen to data points with several variables. Each of the given
+// number of random subsets of p+1 data points, p being the number of variables, determines the
+// coefficients of the hyperplane going through them, and the estimate is the spatial median of these
+// coefficient vectors, that is the point minimising the sum of the Euclidean distances to them. The
+// coefficients are returned in the order of GetCoeffs, the offset first. Subsets whose variables are
+// collinear are skipped.
+func TheilSenMultivariate(data []DataPoint, subsets int, seed int64) ([]float64, error) {
+	if len(data) == 0 || subsets < 1 {
+		return nil, ErrNotEnoughData
+	}
+	if err := DataPoints(data).Validate(); err != nil {
+		return nil, err
+	}
+	p := len(data[0].Variables) + 1
+	if len(data) < p {
+		return nil, &InsufficientObservationsError{Have: len(data), Need: p}
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	variables, observed := mat.NewDense(p, p, nil), mat.NewVecDense(p, nil)
+	var estimates [][]float64
+	for s := 0; s < subsets; s++ {
+		for i, k := range rnd.Perm(len(data))[:p] {
+			variables.Set(i, 0, 1)
+			for j, val := range data[k].Variables {
+				variables.Set(i, j+1, val)
+			}
+			observed.SetVec(i, data[k].Observed)
+		}
+		var lu mat.LU
+		lu.Factorize(variables)
+		if lu.Cond() > 1e12 {
+			continue
+		}
+		c := mat.NewVecDense(p, nil)
+		if err := lu.SolveVecTo(c, false, observed); err != nil {
+			continue
+		}
+		estimates = append(estimates, c.RawVector().Data)
+	}
+	if len(estimates) == 0 {
+		return nil, ErrSingularMatrix
+	}
+	return spatialMedian(estimates), nil
+}
+
+// spatialMedian returns the geometric median of points with the Weiszfeld algorithm, starting from
+// the coordinate-wise median.
+func spatialMedian(points [][]float64) []float64 {
+	dim := len(points[0])
+	current := make([]float64, dim)
+	column := make([]float64, len(points))
+	for j := range current {
+		for i, pt := range points {
+			column[i] = pt[j]
+		}
+		current[j] = median(column)
+	}
+
+	next := make([]float64, dim)
+	for iter := 0; iter < maxWeiszfeldIterations; iter++ {
+		var total float64
+		for j := range next {
+			next[j] = 0
+		}
+		for _, pt := range points {
+			var dist float64
+			for j, val := range pt {
+				dist += (val - current[j]) * (val - current[j])
+			}
+			dist = math.Sqrt(dist)
+			// a point at the current estimate has an infinite weight, skip it rather than divide by 0
+			if dist < weiszfeldTolerance {
+				continue
+			}
+			total += 1 / dist
+			for j, val := range pt {
+				next[j] += val / dist
+			}
+		}
+		if total == 0 {
+			break
+		}
+		var change float64
+		for j := range next {
+			next[j] /= total
+			change += math.Abs(next[j] - current[j])
+		}
+		current, next = next, current
+		if change < weiszfeldTolerance {
+			break
+		}
+	}
+	return current
+}
+
+// median returns the median of values, which get sorted in place.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly between them.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
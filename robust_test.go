@@ -0,0 +1,87 @@
+package regression
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTheilSen(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 30; i++ {
+		x := float64(i)
+		data = append(data, DataPoint{Observed: 2*x + 5, Variables: []float64{x}})
+	}
+	// contaminate the line with a few extreme outliers
+	for _, i := range []int{25, 27, 29} {
+		data[i].Observed = -500
+	}
+
+	slope, intercept, err := TheilSen(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(slope-2) > 0.01 || math.Abs(intercept-5) > 0.1 {
+		t.Errorf("Expected slope 2 and intercept 5, got %.2f and %.2f", slope, intercept)
+	}
+
+	r := &Regression{}
+	r.Train(data...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.Coeff(1)-2) < 1 {
+		t.Errorf("Expected the OLS slope to drift, got %.2f", r.Coeff(1))
+	}
+
+	if _, _, err := TheilSen([]DataPoint{{Observed: 1, Variables: []float64{1, 2}}}); err != ErrNotSingleVariable {
+		t.Errorf("Expected %v, got %v", ErrNotSingleVariable, err)
+	}
+}
+
+func TestTheilSenMultivariate(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 40; i++ {
+		x, y := float64(i%8), float64(i/8)
+		data = append(data, DataPoint{Observed: 1 + 2*x - 3*y, Variables: []float64{x, y}})
+	}
+	// contaminate the plane with extreme outliers
+	for _, i := range []int{3, 11, 17, 26, 35} {
+		data[i].Observed = 1000
+	}
+
+	c, err := TheilSenMultivariate(data, 500, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{1, 2, -3}
+	for i := range expected {
+		if math.Abs(c[i]-expected[i]) > 0.05 {
+			t.Errorf("Expected coefficients %v, got %v", expected, c)
+			break
+		}
+	}
+
+	// with one variable, it agrees with the pairwise slopes of TheilSen
+	var line []DataPoint
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		line = append(line, DataPoint{Observed: 5 + 2*x + math.Sin(x), Variables: []float64{x}})
+	}
+	line[4].Observed = -100
+	slope, intercept, err := TheilSen(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = TheilSenMultivariate(line, 1000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(c[1]-slope) > 0.2 || math.Abs(c[0]-intercept) > 1 {
+		t.Errorf("Expected a slope close to %.2f and intercept close to %.2f, got %v", slope, intercept, c)
+	}
+
+	if _, err := TheilSenMultivariate(data[:2], 10, 1); !errors.Is(err, ErrTooManyVars) {
+		t.Errorf("Expected %v, got %v", ErrTooManyVars, err)
+	}
+}
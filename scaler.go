@@ -0,0 +1,96 @@
+package regression
+
+import "math"
+
+// Scaler standardizes the variables of data points to zero mean and unit standard deviation.
+// Its fields can be marshaled, e.g. to JSON, so that the scaling fitted at training time can be
+// reapplied at inference time in another process.
+type Scaler struct {
+	Means   []float64 `json:"means"`
+	StdDevs []float64 `json:"std_devs"`
+}
+
+// Fit computes the mean and the standard deviation of each variable of the data points.
+func (s *Scaler) Fit(d []DataPoint) error {
+	if len(d) == 0 {
+		return ErrNotEnoughData
+	}
+	if err := DataPoints(d).Validate(); err != nil {
+		return err
+	}
+
+	n := len(d[0].Variables)
+	s.Means = make([]float64, n)
+	s.StdDevs = make([]float64, n)
+	for _, p := range d {
+		for j, val := range p.Variables {
+			s.Means[j] += val
+		}
+	}
+	for j := range s.Means {
+		s.Means[j] /= float64(len(d))
+	}
+	for _, p := range d {
+		for j, val := range p.Variables {
+			s.StdDevs[j] += (val - s.Means[j]) * (val - s.Means[j])
+		}
+	}
+	for j := range s.StdDevs {
+		s.StdDevs[j] = math.Sqrt(s.StdDevs[j] / float64(len(d)))
+		if s.StdDevs[j] == 0 {
+			// constant variables are only centered
+			s.StdDevs[j] = 1
+		}
+	}
+	return nil
+}
+
+// Transform returns a copy of the data points with standardized variables.
+// The observed values are left untouched.
+func (s *Scaler) Transform(d []DataPoint) ([]DataPoint, error) {
+	return s.apply(d, s.TransformVars)
+}
+
+// InverseTransform returns a copy of the data points with the standardization of the variables undone.
+func (s *Scaler) InverseTransform(d []DataPoint) ([]DataPoint, error) {
+	return s.apply(d, s.InverseTransformVars)
+}
+
+// TransformVars returns the standardized variables, e.g. before calling Predict on a model trained on
+// transformed data points.
+func (s *Scaler) TransformVars(vars []float64) ([]float64, error) {
+	if len(vars) != len(s.Means) {
+		return nil, ErrVariableCount
+	}
+	scaled := make([]float64, len(vars))
+	for j, val := range vars {
+		scaled[j] = (val - s.Means[j]) / s.StdDevs[j]
+	}
+	return scaled, nil
+}
+
+// InverseTransformVars returns the variables with their standardization undone.
+func (s *Scaler) InverseTransformVars(vars []float64) ([]float64, error) {
+	if len(vars) != len(s.Means) {
+		return nil, ErrVariableCount
+	}
+	unscaled := make([]float64, len(vars))
+	for j, val := range vars {
+		unscaled[j] = val*s.StdDevs[j] + s.Means[j]
+	}
+	return unscaled, nil
+}
+
+func (s *Scaler) apply(d []DataPoint, fn func([]float64) ([]float64, error)) ([]DataPoint, error) {
+	transformed := make([]DataPoint, len(d))
+	for i, p := range d {
+		vars, err := fn(p.Variables)
+		if err != nil {
+			return nil, err
+		}
+		p.Variables = vars
+		p.Crosses = nil
+		transformed[i] = p
+	}
+	return transformed, nil
+}
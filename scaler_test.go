@@ -0,0 +1,63 @@
+package regression
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestScaler(t *testing.T) {
+	d := DataPoints{}.
+		Add(1, 10, 0.1, 5).
+		Add(2, 20, 0.4, 5).
+		Add(3, 60, 0.2, 5)
+
+	s := &Scaler{}
+	if err := s.Fit(d); err != nil {
+		t.Fatal(err)
+	}
+	transformed, err := s.Transform(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := 0; j < 2; j++ {
+		var mean, variance float64
+		for _, p := range transformed {
+			mean += p.Variables[j] / 3
+		}
+		for _, p := range transformed {
+			variance += (p.Variables[j] - mean) * (p.Variables[j] - mean) / 3
+		}
+		if math.Abs(mean) > 1e-12 || math.Abs(variance-1) > 1e-12 {
+			t.Errorf("Expected variable %d to be standardized, got mean %v and variance %v", j, mean, variance)
+		}
+	}
+
+	// persist the scaler, as a serving process would load it
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Scaler{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := loaded.InverseTransform(transformed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range restored {
+		if p.Observed != d[i].Observed {
+			t.Errorf("Expected observed %v, got %v", d[i].Observed, p.Observed)
+		}
+		for j, val := range p.Variables {
+			if math.Abs(val-d[i].Variables[j]) > 1e-12 {
+				t.Errorf("Expected variables %v, got %v", d[i].Variables, p.Variables)
+			}
+		}
+	}
+
+	if _, err := s.TransformVars([]float64{1}); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+}
@@ -0,0 +1,375 @@
+package regression
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Criterion is a measure of the quality of a fit which accounts for its number of coefficients, used
+// to compare models with different features.
+type Criterion int
+
+const (
+	// AIC is the Akaike information criterion, n ln(SSE/n) + 2k, k being the number of coefficients.
+	AIC Criterion = iota
+	// BIC is the Bayesian information criterion, n ln(SSE/n) + k ln(n), which favors smaller models.
+	BIC
+	// AdjustedR2 is R2 adjusted for the number of coefficients, 1 - (SSE/(n-k))/(SST/(n-1)).
+	AdjustedR2
+)
+
+// score returns the criterion for a fit with k coefficients, offset included, lower being better.
+func (c Criterion) score(sse, sst float64, n, k int) float64 {
+	nf, kf := float64(n), float64(k)
+	switch c {
+	case AIC:
+		return nf*math.Log(sse/nf) + 2*kf
+	case BIC:
+		return nf*math.Log(sse/nf) + kf*math.Log(nf)
+	default:
+		return (sse / (nf - kf)) / (sst / (nf - 1))
+	}
+}
+
+// selection holds the design of a regression, whitened by the weights, to fit subsets of its features.
+type selection struct {
+	variables *mat.Dense
+	observed  *mat.Dense
+	sst       float64
+}
+
+// newSelection prepares the selection of the features of the regression, which must be an ordinary,
+// possibly weighted, least squares fit.
+func (r *Regression) newSelection() (*selection, error) {
+	if !r.initialised {
+		return nil, ErrNotEnoughData
+	}
+	if r.stream != nil {
+		return nil, ErrNoTrainingData
+	}
+	if r.nonNegative || r.penalty != NoRegularization || r.quantileFit || r.family != nil || len(r.bounds) > 0 || r.errorCov != nil {
+		return nil, ErrUnsupportedFit
+	}
+	r.applyCrosses()
+	variables, observed := r.designMatrix()
+	n, p := variables.Dims()
+	if n < p {
+		return nil, &InsufficientObservationsError{Have: n, Need: p}
+	}
+
+	s := &selection{variables: variables, observed: observed}
+	weights := r.dataWeights()
+	if weights != nil {
+		s.variables, s.observed = weightRows(variables, weights), weightRows(observed, weights)
+	}
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+	var total, wtotal float64
+	for i := 0; i < n; i++ {
+		total += weight(i) * observed.At(i, 0)
+		wtotal += weight(i)
+	}
+	for i := 0; i < n; i++ {
+		s.sst += weight(i) * math.Pow(observed.At(i, 0)-total/wtotal, 2)
+	}
+	return s, nil
+}
+
+// fit returns the least squares coefficients using only the features in subset, the offset being
+// always included, along with the residual sum of squares.
+func (s *selection) fit(subset []bool) (c []float64, sse float64) {
+	columns := append([]bool{true}, subset...)
+	c = solveSubset(s.variables, s.observed, columns)
+	n, p := s.variables.Dims()
+	for i := 0; i < n; i++ {
+		e := s.observed.At(i, 0) - mat.Dot(s.variables.RowView(i), mat.NewVecDense(p, c))
+		sse += e * e
+	}
+	return c, sse
+}
+
+// score returns the criterion of the fit on the features in subset.
+func (s *selection) score(subset []bool, criterion Criterion) float64 {
+	_, sse := s.fit(subset)
+	n, _ := s.variables.Dims()
+	k := 1
+	for _, ok := range subset {
+		if ok {
+			k++
+		}
+	}
+	return criterion.score(sse, s.sst, n, k)
+}
+
+// pValues returns the p-values of the t tests of the coefficients of the features in subset, keyed by
+// feature.
+func (s *selection) pValues(subset []bool) (map[int]float64, error) {
+	c, sse := s.fit(subset)
+	n, _ := s.variables.Dims()
+	cols := []int{0}
+	for j, ok := range subset {
+		if ok {
+			cols = append(cols, j+1)
+		}
+	}
+	if n <= len(cols) {
+		return nil, &InsufficientObservationsError{Have: n, Need: len(cols) + 1}
+	}
+	reduced := mat.NewDense(n, len(cols), nil)
+	for k, j := range cols {
+		reduced.SetCol(k, mat.Col(nil, j, s.variables))
+	}
+	xtx := mat.NewSymDense(len(cols), nil)
+	xtx.SymOuterK(1, reduced.T())
+	var chol mat.Cholesky
+	if !chol.Factorize(xtx) {
+		return nil, ErrSingularMatrix
+	}
+	inv := new(mat.SymDense)
+	if err := chol.InverseTo(inv); err != nil {
+		return nil, ErrSingularMatrix
+	}
+
+	df := float64(n - len(cols))
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}
+	pValues := make(map[int]float64, len(cols)-1)
+	for k, j := range cols[1:] {
+		t := c[j] / math.Sqrt(sse/df*inv.At(k+1, k+1))
+		pValues[j-1] = 2 * dist.Survival(math.Abs(t))
+	}
+	return pValues, nil
+}
+
+// runSubset runs the regression with the coefficients of the features outside subset fixed to 0, and
+// returns the indices of the features in subset, in the order of GetCoeffs.
+func (r *Regression) runSubset(subset []bool) ([]int, error) {
+	r.fixed = map[int]float64{}
+	var selected []int
+	for j, ok := range subset {
+		if ok {
+			selected = append(selected, j+1)
+		} else {
+			r.fixed[j+1] = 0
+		}
+	}
+	if err := r.Run(); err != nil {
+		return nil, err
+	}
+	sort.Ints(selected)
+	return selected, nil
+}
+
+// RunStepwiseForward selects features greedily: starting from the offset alone, it adds in turn the
+// variable or feature cross output which improves the criterion the most, until none does. The
+// regression is then run with the coefficients of the other features fixed to 0, replacing any set
+// with FixCoeff, and the indices of the selected features are returned in the order of GetCoeffs.
+func (r *Regression) RunStepwiseForward(criterion Criterion) ([]int, error) {
+	if criterion < AIC || criterion > AdjustedR2 {
+		return nil, ErrUnknownCriterion
+	}
+	s, err := r.newSelection()
+	if err != nil {
+		return nil, err
+	}
+	n, p := s.variables.Dims()
+	subset := make([]bool, p-1)
+	best := s.score(subset, criterion)
+	for size := 1; size < p && size < n-1; size++ {
+		candidate := -1
+		for j := range subset {
+			if subset[j] {
+				continue
+			}
+			subset[j] = true
+			if score := s.score(subset, criterion); score < best {
+				best, candidate = score, j
+			}
+			subset[j] = false
+		}
+		if candidate < 0 {
+			break
+		}
+		subset[candidate] = true
+	}
+	return r.runSubset(subset)
+}
+
+// Elimination is a step of RunBackwardElimination: the feature at Index, in the order of GetCoeffs,
+// was dropped as its coefficient had the highest p-value, PValue.
+type Elimination struct {
+	Index  int
+	PValue float64
+}
+
+// RunBackwardElimination selects features starting from all the variables and feature cross outputs,
+// and dropping in turn the one whose coefficient is the least significant, until the p-values of the
+// t tests of all the remaining ones are at most alpha, e.g. 0.05. The regression is then run with the
+// coefficients of the dropped features fixed to 0, replacing any set with FixCoeff. The indices of the
+// remaining features are returned in the order of GetCoeffs, along with the history of eliminations.
+func (r *Regression) RunBackwardElimination(alpha float64) ([]int, []Elimination, error) {
+	if alpha <= 0 || alpha >= 1 {
+		return nil, nil, ErrInvalidProbability
+	}
+	s, err := r.newSelection()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, p := s.variables.Dims()
+	subset := make([]bool, p-1)
+	for j := range subset {
+		subset[j] = true
+	}
+
+	var history []Elimination
+	for len(history) < len(subset) {
+		pValues, err := s.pValues(subset)
+		if err != nil {
+			return nil, nil, err
+		}
+		worst := -1
+		for j, pValue := range pValues {
+			// break ties by index, as the iteration order of maps is random
+			if worst < 0 || pValue > pValues[worst] || (pValue == pValues[worst] && j < worst) {
+				worst = j
+			}
+		}
+		if pValues[worst] <= alpha {
+			break
+		}
+		subset[worst] = false
+		history = append(history, Elimination{Index: worst + 1, PValue: pValues[worst]})
+	}
+
+	selected, err := r.runSubset(subset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return selected, history, nil
+}
+
+// maxBestSubsetFeatures is the number of features above which RunBestSubset refuses to search.
+const maxBestSubsetFeatures = 20
+
+// SubsetFit is the best fit found by RunBestSubset for a number of features.
+type SubsetFit struct {
+	Indices []int   // indices of the features in the order of GetCoeffs, the offset excluded
+	SSE     float64 // residual sum of squares
+	Score   float64 // criterion of the fit
+}
+
+// RunBestSubset selects features by searching, for each number of features, the subset of the
+// variables and feature cross outputs with the lowest residual sum of squares, then picking the best
+// of these subsets by the criterion. The search is exhaustive, but prunes the subsets of a set of
+// features whose residual sum of squares is already above the best ones, as it can only increase when
+// features are removed. It is limited to 20 features. The regression is then run with the coefficients
+// of the other features fixed to 0, replacing any set with FixCoeff. The indices of the selected
+// features are returned in the order of GetCoeffs, along with the best fit of each size, from 0 feature
+// to all of them.
+func (r *Regression) RunBestSubset(criterion Criterion) ([]int, []SubsetFit, error) {
+	if criterion < AIC || criterion > AdjustedR2 {
+		return nil, nil, ErrUnknownCriterion
+	}
+	s, err := r.newSelection()
+	if err != nil {
+		return nil, nil, err
+	}
+	n, p := s.variables.Dims()
+	m := p - 1
+	if m > maxBestSubsetFeatures {
+		return nil, nil, ErrTooManyFeatures
+	}
+
+	// the residual sums of squares are computed from XᵀX and Xᵀy, which is much faster than refitting
+	gram := mat.NewSymDense(p, nil)
+	gram.SymOuterK(1, s.variables.T())
+	xty := mat.NewVecDense(p, nil)
+	xty.MulVec(s.variables.T(), s.observed.ColView(0))
+	yty := mat.Dot(s.observed.ColView(0), s.observed.ColView(0))
+	sse := func(subset []bool) float64 {
+		cols := []int{0}
+		for j, ok := range subset {
+			if ok {
+				cols = append(cols, j+1)
+			}
+		}
+		a := mat.NewSymDense(len(cols), nil)
+		b := mat.NewVecDense(len(cols), nil)
+		for k, j := range cols {
+			b.SetVec(k, xty.AtVec(j))
+			for l := k; l < len(cols); l++ {
+				a.SetSym(k, l, gram.At(j, cols[l]))
+			}
+		}
+		var chol mat.Cholesky
+		c := mat.NewVecDense(len(cols), nil)
+		if !chol.Factorize(a) || chol.SolveVecTo(c, b) != nil {
+			return math.Inf(1)
+		}
+		return math.Max(yty-mat.Dot(c, b), 0)
+	}
+
+	best := make([]SubsetFit, m+1)
+	for k := range best {
+		best[k].SSE = math.Inf(1)
+	}
+	record := func(subset []bool, size int, value float64) {
+		if value < best[size].SSE {
+			best[size].SSE = value
+			best[size].Indices = best[size].Indices[:0]
+			for j, ok := range subset {
+				if ok {
+					best[size].Indices = append(best[size].Indices, j+1)
+				}
+			}
+		}
+	}
+
+	// the features before next are decided, subset holding them and all the following ones
+	subset := make([]bool, m)
+	for j := range subset {
+		subset[j] = true
+	}
+	var search func(next, size int)
+	search = func(next, size int) {
+		bound := sse(subset)
+		record(subset, size, bound)
+		promising := false
+		for k := size - (m - next); k < size; k++ {
+			promising = promising || bound < best[k].SSE
+		}
+		if !promising {
+			return
+		}
+		for j := next; j < m; j++ {
+			subset[j] = false
+			search(j+1, size-1)
+			subset[j] = true
+		}
+	}
+	search(0, m)
+
+	winner := 0
+	for k := range best {
+		best[k].Indices = append([]int(nil), best[k].Indices...)
+		best[k].Score = criterion.score(best[k].SSE, s.sst, n, k+1)
+		if k+1 < n && best[k].Score < best[winner].Score {
+			winner = k
+		}
+	}
+	chosen := make([]bool, m)
+	for _, j := range best[winner].Indices {
+		chosen[j-1] = true
+	}
+	selected, err := r.runSubset(chosen)
+	if err != nil {
+		return nil, nil, err
+	}
+	return selected, best, nil
+}
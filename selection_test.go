@@ -0,0 +1,133 @@
+package regression
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// selectionData returns data points with 8 variables, of which only 0, 3 and 5 are relevant.
+func selectionData() []DataPoint {
+	rnd := rand.New(rand.NewSource(1))
+	var data []DataPoint
+	for i := 0; i < 100; i++ {
+		vars := make([]float64, 8)
+		for j := range vars {
+			vars[j] = rnd.NormFloat64()
+		}
+		data = append(data, DataPoint{Observed: 1 + 3*vars[0] - 2*vars[3] + vars[5] + 0.3*rnd.NormFloat64(), Variables: vars})
+	}
+	return data
+}
+
+// indexSet returns the set of the given indices.
+func indexSet(indices []int) map[int]bool {
+	set := map[int]bool{}
+	for _, i := range indices {
+		set[i] = true
+	}
+	return set
+}
+
+func TestRunStepwiseForward(t *testing.T) {
+	for _, criterion := range []Criterion{AIC, BIC, AdjustedR2} {
+		r := &Regression{}
+		r.Train(selectionData()...)
+		r.AddCross(MultiplierCross(0, 1))
+		selected, err := r.RunStepwiseForward(criterion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the coefficients of the variables 0, 3 and 5 are at indices 1, 4 and 6
+		set := indexSet(selected)
+		if !set[1] || !set[4] || !set[6] || (criterion == BIC && len(selected) != 3) {
+			t.Errorf("Expected the relevant variables to be selected by criterion %d, got %v", criterion, selected)
+		}
+		coeffs := r.GetCoeffs()
+		if len(coeffs) != 10 {
+			t.Fatalf("Expected 10 coefficients, got %v", coeffs)
+		}
+		for j, c := range coeffs[1:] {
+			if c != 0 && !set[j+1] {
+				t.Errorf("Expected the coefficients of unselected features to be 0, got %v", coeffs)
+				break
+			}
+		}
+	}
+
+	r := &Regression{}
+	r.Train(selectionData()...)
+	if _, err := r.RunStepwiseForward(Criterion(42)); err != ErrUnknownCriterion {
+		t.Errorf("Expected %v, got %v", ErrUnknownCriterion, err)
+	}
+}
+
+func TestRunBackwardElimination(t *testing.T) {
+	r := &Regression{}
+	r.Train(selectionData()...)
+	selected, history, err := r.RunBackwardElimination(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := indexSet(selected)
+	if !set[1] || !set[4] || !set[6] {
+		t.Errorf("Expected the relevant variables to remain, got %v", selected)
+	}
+	if len(selected)+len(history) != 8 {
+		t.Errorf("Expected every variable to be either selected or eliminated, got %v and %v", selected, history)
+	}
+	for i, step := range history {
+		if set[step.Index] || step.PValue <= 0.01 {
+			t.Errorf("Expected eliminated variables to be insignificant, got %v", step)
+		}
+		if r.Coeff(step.Index) != 0 {
+			t.Errorf("Expected the coefficient of eliminated variable %d to be 0, got %v", step.Index, r.GetCoeffs())
+		}
+		if i > 0 && history[i-1].Index == step.Index {
+			t.Errorf("Expected each variable to be eliminated once, got %v", history)
+		}
+	}
+
+	if _, _, err := r.RunBackwardElimination(0); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+}
+
+func TestRunBestSubset(t *testing.T) {
+	r := &Regression{}
+	r.Train(selectionData()...)
+	r.AddCross(MultiplierCross(0, 1))
+	selected, fits, err := r.RunBestSubset(BIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set := indexSet(selected); len(selected) != 3 || !set[1] || !set[4] || !set[6] {
+		t.Errorf("Expected the relevant variables to be selected, got %v", selected)
+	}
+	if len(fits) != 10 {
+		t.Fatalf("Expected the best fit of 0 to 9 features, got %v", fits)
+	}
+
+	// compare with a brute force search
+	s, err := r.newSelection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for mask := 0; mask < 1<<9; mask++ {
+		subset := make([]bool, 9)
+		size := 0
+		for j := range subset {
+			subset[j] = mask&(1<<j) != 0
+			if subset[j] {
+				size++
+			}
+		}
+		if _, sse := s.fit(subset); sse < fits[size].SSE*(1-1e-9) {
+			t.Errorf("Expected the best fit of %d features to have a SSE of at most %v, got %v", size, sse, fits[size].SSE)
+		}
+	}
+	for k := 1; k < len(fits); k++ {
+		if len(fits[k].Indices) != k || fits[k].SSE > fits[k-1].SSE {
+			t.Errorf("Expected %d features with a lower SSE than the best with one less, got %v", k, fits[k])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// accumulator holds the running sums of a streamed regression.
+type accumulator struct {
+	xtx          *mat.SymDense // XᵀWX
+	xty          *mat.VecDense // XᵀWy
+	observations int
+	weights      float64 // Σw
+	sum          float64 // Σwy
+	sumSquares   float64 // Σwy²
+}
+
+// Accumulate folds data points into running XᵀX and Xᵀy sums and discards them, so that the memory
+// used does not grow with the number of observations. Data points added with Train are folded in by the
+// next call, or by Run. The feature crosses and the response transformation must be set up beforehand:
+// a batch is rejected as a whole, with ErrMismatchedCrosses if crosses were registered since the first
+// call, ErrVariableCount or ErrNegativeWeight. Accumulated fits can't be combined with fixed,
+// non-negative or bounded coefficients, regularization, quantiles, distributions nor correlated errors,
+// Run returning ErrUnsupportedFit.
+//
+// Once data points are accumulated, Run solves the normal equations from the sums. The coefficients
+// and R2 (which is weighted if data points have weights) are available, but the methods requiring
+// the individual data points return ErrNoTrainingData.
+func (r *Regression) Accumulate(d ...DataPoint) error {
+	d = append(append([]DataPoint(nil), r.Data...), d...)
+	if len(d) == 0 {
+		return nil
+	}
+	numVars, width := r.numVars, 0
+	if r.stream == nil {
+		numVars = len(d[0].Variables)
+	} else {
+		width = r.stream.xtx.SymmetricDim()
+	}
+	rows := make([][]float64, len(d))
+	for i, p := range d {
+		if len(p.Variables) != numVars {
+			return ErrVariableCount
+		}
+		if p.Weight < 0 {
+			return ErrNegativeWeight
+		}
+		rows[i] = append([]float64{1}, r.features(p.Variables)...)
+		if i == 0 && r.stream == nil {
+			width = len(rows[i])
+		}
+		if len(rows[i]) != width {
+			return ErrMismatchedCrosses
+		}
+	}
+
+	r.Data = nil
+	if r.stream == nil {
+		r.numVars = numVars
+		r.stream = &accumulator{
+			xtx: mat.NewSymDense(width, nil),
+			xty: mat.NewVecDense(width, nil),
+		}
+	}
+	for i, p := range d {
+		w, y := p.weight(), r.response(p.Observed)
+		x := mat.NewVecDense(width, rows[i])
+		r.stream.xtx.SymRankOne(r.stream.xtx, w, x)
+		r.stream.xty.AddScaledVec(r.stream.xty, w*y, x)
+		r.stream.observations++
+		r.stream.weights += w
+		r.stream.sum += w * y
+		r.stream.sumSquares += w * y * y
+	}
+	if r.stream.observations > 2 {
+		r.initialised = true
+	}
+	return nil
+}
+
+// checkStream validates the settings of an accumulated fit, which solves the unconstrained normal
+// equations.
+func (r *Regression) checkStream() error {
+	if r.stream != nil && (len(r.fixed) > 0 || !r.leastSquares()) {
+		return ErrUnsupportedFit
+	}
+	return nil
+}
+
+// runAccumulated solves the normal equations XᵀWX c = XᵀWy from the accumulated sums.
+func (r *Regression) runAccumulated() error {
+	s := r.stream
+	p := s.xtx.SymmetricDim()
+	if s.observations < p {
+		return &InsufficientObservationsError{Have: s.observations, Need: p}
+	}
+
+	var chol mat.Cholesky
+	if !chol.Factorize(s.xtx) {
+		return ErrSingularMatrix
+	}
+	c := mat.NewVecDense(p, nil)
+	if err := chol.SolveVecTo(c, s.xty); err != nil {
+		return ErrSingularMatrix
+	}
+
+	r.coeff = make(map[int]float64, p)
+	for i := 0; i < p; i++ {
+		r.coeff[i] = c.AtVec(i)
+	}
+	r.variables, r.observed, r.weights = nil, nil, nil
+	r.overfit = s.observations-p < minResidualDF
+	r.xtxInv = nil
+
+	// SSE = yᵀWy - 2cᵀXᵀWy + cᵀXᵀWXc, which is yᵀWy - cᵀXᵀWy at the solution
+	sse := s.sumSquares - mat.Dot(c, s.xty)
+	sst := s.sumSquares - s.sum*s.sum/s.weights
+	r.R2 = 1 - math.Max(sse, 0)/sst
+	r.weightedR2 = r.R2
+	r.AdjustedR2 = adjustR2(r.R2, s.observations, p-1)
+	r.Ready = true
+	return nil
+}
@@ -0,0 +1,148 @@
+package regression
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestAccumulate(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 30; i++ {
+		x, y := float64(i), math.Sin(float64(i))
+		data = append(data, DataPoint{Observed: 2 + x + 3*y + 0.1*x*x + math.Cos(3*x), Variables: []float64{x, y}})
+	}
+
+	batch := &Regression{}
+	batch.AddCross(PowCross(0, 2))
+	batch.Train(data...)
+	if err := batch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	streamed := &Regression{}
+	streamed.AddCross(PowCross(0, 2))
+	streamed.Train(data[:5]...)
+	for _, d := range data[5:] {
+		if err := streamed.Accumulate(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := streamed.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed.Data) != 0 {
+		t.Errorf("Expected the data points to be discarded, got %d", len(streamed.Data))
+	}
+
+	for i, c := range batch.GetCoeffs() {
+		if math.Abs(streamed.Coeff(i)-c) > 1e-6 {
+			t.Errorf("Expected coefficients %v, got %v", batch.GetCoeffs(), streamed.GetCoeffs())
+		}
+	}
+	if math.Abs(streamed.R2-batch.R2) > 1e-9 {
+		t.Errorf("Expected R^2 %v, got %v", batch.R2, streamed.R2)
+	}
+	if _, err := streamed.DesignMatrix(); err != ErrNoTrainingData {
+		t.Errorf("Expected %v, got %v", ErrNoTrainingData, err)
+	}
+	if err := streamed.Accumulate(DataPoint{Observed: 1, Variables: []float64{1}}); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+}
+
+func TestAccumulateUnsupported(t *testing.T) {
+	for name, setup := range map[string]func(r *Regression){
+		"fixed":        func(r *Regression) { r.FixCoeff(1, 7) },
+		"non-negative": func(r *Regression) { r.SetNonNegative(true) },
+		"bounded":      func(r *Regression) { r.SetBound(1, -10, 10) },
+		"penalized":    func(r *Regression) { r.SetRegularization(L2, 0.1) },
+		"quantile":     func(r *Regression) { r.SetQuantile(0.5) },
+		"distribution": func(r *Regression) { r.SetFamily(Gaussian) },
+	} {
+		r := &Regression{}
+		setup(r)
+		for i := 0; i < 10; i++ {
+			x := float64(i)
+			if err := r.Accumulate(DataPoint{Observed: 5 - 2*x, Variables: []float64{x}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.Run(); err != ErrUnsupportedFit {
+			t.Errorf("Expected %v with a %s fit, got %v", ErrUnsupportedFit, name, err)
+		}
+	}
+}
+
+func TestAccumulateBatches(t *testing.T) {
+	var data []DataPoint
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		data = append(data, DataPoint{Observed: 1 + 2*x + math.Sin(x), Variables: []float64{x}})
+	}
+	batch := &Regression{}
+	batch.Train(data...)
+	if err := batch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Regression{}
+	r.Train(data[:5]...)
+	if err := r.Accumulate(data[5:10]...); err != nil {
+		t.Fatal(err)
+	}
+
+	// an invalid batch leaves the sums untouched
+	invalid := append(append([]DataPoint(nil), data[10:15]...), DataPoint{Observed: 1, Variables: []float64{1, 2}})
+	if err := r.Accumulate(invalid...); err != ErrVariableCount {
+		t.Errorf("Expected %v, got %v", ErrVariableCount, err)
+	}
+	invalid[len(invalid)-1] = DataPoint{Observed: 1, Variables: []float64{1}, Weight: -1}
+	if err := r.Accumulate(invalid...); err != ErrNegativeWeight {
+		t.Errorf("Expected %v, got %v", ErrNegativeWeight, err)
+	}
+	if err := r.Accumulate(data[10:15]...); err != nil {
+		t.Fatal(err)
+	}
+
+	// data points trained since the last call are folded in by Run
+	r.Train(data[15:]...)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range batch.GetCoeffs() {
+		if math.Abs(r.Coeff(i)-c) > 1e-6 {
+			t.Errorf("Expected coefficients %v, got %v", batch.GetCoeffs(), r.GetCoeffs())
+		}
+	}
+
+	r.AddCross(PowCross(0, 2))
+	if err := r.Accumulate(data[0]); err != ErrMismatchedCrosses {
+		t.Errorf("Expected %v, got %v", ErrMismatchedCrosses, err)
+	}
+}
+
+func BenchmarkAccumulate(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			var before, after runtime.MemStats
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				r := &Regression{}
+				for j := 0; j < n; j++ {
+					x := float64(j)
+					_ = r.Accumulate(DataPoint{Observed: 2*x + 1, Variables: []float64{x, math.Sin(x)}})
+				}
+				if err := r.Run(); err != nil {
+					b.Fatal(err)
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				runtime.KeepAlive(r)
+			}
+			b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "retained-bytes")
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package regression
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Summary is the report of a regression: its coefficients with their inference statistics and its
+// goodness-of-fit statistics, on the scale of the fit. It can be rendered with Text, Markdown or LaTeX.
+type Summary struct {
+	Observed         string // name of the observed value, see SetObserved
+	Coefficients     []CoeffSummary
+	Observations     int
+	R2               float64
+	AdjustedR2       float64
+	ResidualStdError float64
+	FTest            FTestResult
+}
+
+// Summary returns the report of the regression, which must be a least squares fit. When no coefficient
+// but the offset is fitted, the F test is NaN on 0 degrees of freedom.
+func (r *Regression) Summary() (Summary, error) {
+	if err := r.checkInference(); err != nil {
+		return Summary{}, err
+	}
+	d, err := r.Diagnostics()
+	if err != nil {
+		return Summary{}, err
+	}
+	coeffs, err := r.coeffReports()
+	if err != nil {
+		return Summary{}, err
+	}
+	test, err := r.FTest()
+	if err == ErrUnsupportedFit {
+		// the offset alone has no F test
+		test = FTestResult{F: math.NaN(), DF2: r.residualDF(), PValue: math.NaN()}
+	} else if err != nil {
+		return Summary{}, err
+	}
+	return Summary{
+		Observed:         r.responseName(),
+		Coefficients:     coeffs,
+		Observations:     d.Observations,
+		R2:               d.R2,
+		AdjustedR2:       d.AdjustedR2,
+		ResidualStdError: d.ResidualStdError,
+		FTest:            test,
+	}, nil
+}
+
+// summaryHeader holds the column titles of the coefficients table.
+var summaryHeader = []string{"Estimate", "Std. Error", "t value", "p-value"}
+
+// cells returns the formatted statistics of a coefficient, in the order of summaryHeader.
+func (c CoeffSummary) cells() []string {
+	return []string{formatStat(c.Estimate), formatStat(c.StdError), formatStat(c.TStat), formatStat(c.PValue)}
+}
+
+// footer returns the lines of goodness-of-fit statistics following the coefficients table.
+func (s Summary) footer() []string {
+	return []string{
+		fmt.Sprintf("Observations: %d, R²: %s, adjusted R²: %s", s.Observations, formatStat(s.R2), formatStat(s.AdjustedR2)),
+		fmt.Sprintf("Residual standard error: %s on %d degrees of freedom", formatStat(s.ResidualStdError), s.FTest.DF2),
+		fmt.Sprintf("F-statistic: %s on %d and %d degrees of freedom, p-value: %s", formatStat(s.FTest.F), s.FTest.DF1, s.FTest.DF2, formatStat(s.FTest.PValue)),
+	}
+}
+
+// Text renders the summary as a plain text table aligned with spaces, the names being left-aligned and
+// the numbers right-aligned, followed by the goodness-of-fit statistics.
+func (s Summary) Text() string {
+	rows := [][]string{append([]string{""}, summaryHeader...)}
+	for _, c := range s.Coefficients {
+		rows = append(rows, append([]string{c.Name}, c.cells()...))
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for j, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(s.Observed + "\n")
+	for _, row := range rows {
+		fmt.Fprintf(&sb, "%-*s", widths[0], row[0])
+		for j, cell := range row[1:] {
+			fmt.Fprintf(&sb, "  %*s", widths[j+1], cell)
+		}
+		sb.WriteString("\n")
+	}
+	for _, line := range s.footer() {
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// Markdown renders the summary as a Markdown table, followed by the goodness-of-fit statistics.
+func (s Summary) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "| %s | %s |\n", markdownEscape(s.Observed), strings.Join(summaryHeader, " | "))
+	sb.WriteString("|:---" + strings.Repeat("|---:", len(summaryHeader)) + "|\n")
+	for _, c := range s.Coefficients {
+		fmt.Fprintf(&sb, "| %s | %s |\n", markdownEscape(c.Name), strings.Join(c.cells(), " | "))
+	}
+	sb.WriteString("\n")
+	for _, line := range s.footer() {
+		sb.WriteString(line + "  \n")
+	}
+	return sb.String()
+}
+
+// LaTeX renders the summary as a LaTeX tabular environment, the goodness-of-fit statistics spanning
+// the last rows.
+func (s Summary) LaTeX() string {
+	var sb strings.Builder
+	columns := len(summaryHeader) + 1
+	sb.WriteString("\\begin{tabular}{l" + strings.Repeat("r", len(summaryHeader)) + "}\n\\hline\n")
+	fmt.Fprintf(&sb, "%s & %s \\\\\n\\hline\n", latexEscape(s.Observed), strings.Join(summaryHeader, " & "))
+	for _, c := range s.Coefficients {
+		fmt.Fprintf(&sb, "%s & %s \\\\\n", latexEscape(c.Name), strings.Join(c.cells(), " & "))
+	}
+	sb.WriteString("\\hline\n")
+	for _, line := range s.footer() {
+		fmt.Fprintf(&sb, "\\multicolumn{%d}{l}{%s} \\\\\n", columns, latexEscape(line))
+	}
+	sb.WriteString("\\hline\n\\end{tabular}\n")
+	return sb.String()
+}
+
+// formatStat formats a statistic with 4 significant digits.
+func formatStat(v float64) string {
+	return fmt.Sprintf("%.4g", v)
+}
+
+// markdownEscape escapes the characters of s which delimit the cells of Markdown tables.
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// latexEscaper escapes the characters which are special to LaTeX.
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"{", `\{`,
+	"}", `\}`,
+	"~", `\textasciitilde{}`,
+	"^", `\^{}`,
+	"²", `$^2$`,
+)
+
+// latexEscape escapes the characters of s which are special to LaTeX.
+func latexEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
@@ -0,0 +1,111 @@
+package regression
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func summaryRegression(t *testing.T) *Regression {
+	r := &Regression{}
+	for i := 0; i < 20; i++ {
+		x, z := float64(i), math.Sin(float64(i))
+		r.Train(DataPoint{Observed: 3 + 2*x + 0.01*z + math.Cos(5*x), Variables: []float64{x, z}})
+	}
+	r.AddCross(PowCross(1, 2))
+	r.SetObserved("Sales")
+	r.SetVar(0, "Ad_spend")
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestSummary(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.Summary(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r = summaryRegression(t)
+
+	s, err := r.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Observed != "Sales" || s.Observations != 20 || s.R2 != r.R2 {
+		t.Errorf("Expected Sales, 20 observations and R^2 %v, got %s, %d and %v", r.R2, s.Observed, s.Observations, s.R2)
+	}
+	names := []string{"(Intercept)", "Ad_spend", "x1", "x1^2"}
+	if len(s.Coefficients) != len(names) {
+		t.Fatalf("Expected %d coefficients, got %d", len(names), len(s.Coefficients))
+	}
+	for i, c := range s.Coefficients {
+		stdErr, _ := r.CoeffStdErr(i)
+		p, _ := r.PValue(i)
+		if c.Name != names[i] || c.Estimate != r.Coeff(i) || c.StdError != stdErr || math.Abs(c.PValue-p) > 1e-12 {
+			t.Errorf("Expected coefficient %s = %v (%v, p = %v), got %+v", names[i], r.Coeff(i), stdErr, p, c)
+		}
+	}
+	if test, _ := r.FTest(); s.FTest != test {
+		t.Errorf("Expected F test %+v, got %+v", test, s.FTest)
+	}
+
+	// with the slope fixed, only the offset is fitted
+	r = &Regression{}
+	for i := 0; i < 10; i++ {
+		x := float64(i)
+		r.Train(DataPoint{Observed: 1 - 2*x + math.Sin(x), Variables: []float64{x}})
+	}
+	r.FixCoeff(1, -2)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = r.Summary(); err != nil {
+		t.Fatal(err)
+	}
+	if s.FTest.DF1 != 0 || s.FTest.DF2 != 9 || !math.IsNaN(s.FTest.F) || !math.IsNaN(s.FTest.PValue) {
+		t.Errorf("Expected a NaN F test on 0 and 9 degrees of freedom, got %+v", s.FTest)
+	}
+	if c := s.Coefficients[1]; c.StdError != 0 || !math.IsNaN(c.TStat) || !math.IsNaN(c.PValue) {
+		t.Errorf("Expected NaN statistics for the fixed slope, got %+v", c)
+	}
+}
+
+func TestSummaryFormats(t *testing.T) {
+	s, err := summaryRegression(t).Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := s.Text()
+	lines := strings.Split(text, "\n")
+	if lines[0] != "Sales" || !strings.HasSuffix(lines[1], "Estimate  Std. Error  t value    p-value") {
+		t.Errorf("Expected a title and a header, got:\n%s", text)
+	}
+	// the columns are right-aligned
+	for _, line := range lines[2:6] {
+		if len(line) != len(lines[1]) {
+			t.Errorf("Expected aligned rows, got:\n%s", text)
+			break
+		}
+	}
+	if !strings.HasPrefix(lines[3], "Ad_spend ") || !strings.Contains(text, "on 16 degrees of freedom") {
+		t.Errorf("Expected the coefficients and statistics, got:\n%s", text)
+	}
+
+	markdown := s.Markdown()
+	if !strings.HasPrefix(markdown, "| Sales | Estimate | Std. Error | t value | p-value |\n|:---|---:|---:|---:|---:|\n| (Intercept) | ") {
+		t.Errorf("Expected a Markdown table, got:\n%s", markdown)
+	}
+	if strings.Count(markdown, "\n| ") != 4 {
+		t.Errorf("Expected 4 coefficient rows, got:\n%s", markdown)
+	}
+
+	latex := s.LaTeX()
+	if !strings.HasPrefix(latex, "\\begin{tabular}{lrrrr}\n") || !strings.HasSuffix(latex, "\\end{tabular}\n") {
+		t.Errorf("Expected a tabular environment, got:\n%s", latex)
+	}
+	if !strings.Contains(latex, "Ad\\_spend & ") || !strings.Contains(latex, "x1\\^{}2 & ") || !strings.Contains(latex, "R$^2$") {
+		t.Errorf("Expected escaped names, got:\n%s", latex)
+	}
+}
@@ -0,0 +1,247 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// CVResult holds the metrics of a cross-validation, computed on the held-out data points of each fold,
+// on the original scale of the observed values.
+type CVResult struct {
+	R2   []float64 // coefficient of determination of each fold
+	RMSE []float64 // root mean squared error of each fold
+	MAE  []float64 // mean absolute error of each fold
+
+	R2Mean, R2Std     float64
+	RMSEMean, RMSEStd float64
+	MAEMean, MAEStd   float64
+}
+
+// CrossValidate runs a k-fold cross-validation of the regression: a copy of the training data points is
+// shuffled with the given seed, see DataPoints.Shuffle, and split into k folds of nearly equal sizes,
+// and each fold is predicted by the regression run on the other folds, with the same feature crosses
+// and options. The regression itself is left untouched.
+func CrossValidate(r *Regression, k int, seed int64) (CVResult, error) {
+	if r.stream != nil {
+		return CVResult{}, ErrNoTrainingData
+	}
+	if r.errorCov != nil {
+		return CVResult{}, ErrUnsupportedFit
+	}
+	n := len(r.Data)
+	if k < 2 || k > n {
+		return CVResult{}, ErrInvalidFolds
+	}
+
+	data := append(DataPoints(nil), r.Data...)
+	data.Shuffle(seed)
+	var result CVResult
+	for fold := 0; fold < k; fold++ {
+		start, end := fold*n/k, (fold+1)*n/k
+		train := make([]DataPoint, 0, n-(end-start))
+		train = append(train, data[:start]...)
+		train = append(train, data[end:]...)
+		model := r.withData(train)
+		if err := model.Run(); err != nil {
+			return CVResult{}, err
+		}
+
+		var sse, sae, mean float64
+		for _, d := range data[start:end] {
+			mean += d.Observed
+		}
+		mean /= float64(end - start)
+		var sst float64
+		for _, d := range data[start:end] {
+			predicted, err := model.Predict(d.Variables)
+			if err != nil {
+				return CVResult{}, err
+			}
+			sse += (d.Observed - predicted) * (d.Observed - predicted)
+			sae += math.Abs(d.Observed - predicted)
+			sst += (d.Observed - mean) * (d.Observed - mean)
+		}
+		size := float64(end - start)
+		result.R2 = append(result.R2, 1-sse/sst)
+		result.RMSE = append(result.RMSE, math.Sqrt(sse/size))
+		result.MAE = append(result.MAE, sae/size)
+	}
+	result.R2Mean, result.R2Std = stat.MeanStdDev(result.R2, nil)
+	result.RMSEMean, result.RMSEStd = stat.MeanStdDev(result.RMSE, nil)
+	result.MAEMean, result.MAEStd = stat.MeanStdDev(result.MAE, nil)
+	return result, nil
+}
+
+// ErrorMetrics holds the prediction error metrics of a regression on a set of data points, on the
+// original scale of the observed values.
+type ErrorMetrics struct {
+	RMSE float64 // root mean squared error
+	MAE  float64 // mean absolute error
+	MAPE float64 // mean absolute percentage error, infinite when an observed value is 0
+}
+
+// Metrics returns the prediction error metrics of the regression on its training data points, after Run.
+func (r *Regression) Metrics() (ErrorMetrics, error) {
+	if err := r.requireData(); err != nil {
+		return ErrorMetrics{}, err
+	}
+	return r.Evaluate(r.Data)
+}
+
+// Evaluate returns the prediction error metrics of the regression on data, typically held-out data
+// points which were not used by Run, see Split.
+func (r *Regression) Evaluate(data []DataPoint) (ErrorMetrics, error) {
+	if len(data) == 0 {
+		return ErrorMetrics{}, ErrNotEnoughData
+	}
+	var m ErrorMetrics
+	for _, d := range data {
+		predicted, err := r.Predict(d.Variables)
+		if err != nil {
+			return ErrorMetrics{}, err
+		}
+		e := d.Observed - predicted
+		m.RMSE += e * e
+		m.MAE += math.Abs(e)
+		m.MAPE += math.Abs(e / d.Observed)
+	}
+	n := float64(len(data))
+	m.RMSE = math.Sqrt(m.RMSE / n)
+	m.MAE /= n
+	m.MAPE *= 100 / n
+	return m, nil
+}
+
+// withData returns a regression with the same feature crosses and options as r, which has not run yet
+// and is trained on data. The sums accumulated by r are not shared, so that the copy fits data alone.
+func (r *Regression) withData(data []DataPoint) *Regression {
+	c := *r
+	c.Data, c.initialised, c.Ready, c.stream = nil, false, false, nil
+	c.coeff, c.variables, c.observed, c.xtxInv, c.weights = nil, nil, nil, nil, nil
+	c.R2, c.AdjustedR2, c.weightedR2, c.VarianceObserved, c.VariancePredicted = 0, 0, 0, 0, 0
+	c.Train(append([]DataPoint(nil), data...)...)
+	return &c
+}
+
+// LOOResult holds the metrics of a leave-one-out cross-validation, on the scale of the fit.
+type LOOResult struct {
+	Residuals   []float64 // residual of each data point when predicted by the regression run without it
+	PRESS       float64   // sum of the squared leave-one-out residuals
+	PredictedR2 float64   // 1 - PRESS/SStot
+	RMSE        float64   // root mean squared leave-one-out residual
+}
+
+// LOOCV runs a leave-one-out cross-validation of the regression in one pass after Run: the residual of
+// data point i when left out of the fit is eᵢ/(1-hᵢᵢ), hᵢᵢ being its leverage, so that no refit is
+// needed. It only applies to least squares fits without fixed coefficients. When data points are
+// weighted, the residuals are scaled by the square root of the weights.
+func (r *Regression) LOOCV() (LOOResult, error) {
+	residuals, press, err := r.press()
+	if err != nil {
+		return LOOResult{}, err
+	}
+	_, sst := r.sumsOfSquares()
+	return LOOResult{
+		Residuals:   residuals,
+		PRESS:       press,
+		PredictedR2: 1 - press/sst,
+		RMSE:        math.Sqrt(press / float64(len(residuals))),
+	}, nil
+}
+
+// looResiduals returns the leave-one-out residuals eᵢ/(1-hᵢᵢ) of the last run.
+func (r *Regression) looResiduals() ([]float64, error) {
+	leverage, err := r.leverage()
+	if err != nil {
+		return nil, err
+	}
+	residuals := r.residuals()
+	for i := range residuals {
+		residuals[i] /= 1 - leverage[i]
+	}
+	return residuals, nil
+}
+
+// BootstrapResult holds the percentile confidence intervals of a bootstrap.
+type BootstrapResult struct {
+	CoeffLower []float64 // lower bound of each coefficient, in the order of GetCoeffs
+	CoeffUpper []float64 // upper bound of each coefficient
+	R2Lower    float64
+	R2Upper    float64
+}
+
+// Bootstrap estimates confidence intervals at the given level, e.g. 0.95, for the coefficients and R2
+// of the regression: it is run again on samples resamplings with replacement of the training data
+// points, with the same feature crosses and options, and the bounds are the percentiles of the
+// resulting estimates. The resamplings are spread across workers goroutines, a count below 1
+// defaulting to the number of CPUs; each is seeded from seed and its index, so that the result
+// doesn't depend on the number of workers. The regression itself is left untouched.
+func Bootstrap(r *Regression, samples int, level float64, seed int64, workers int) (BootstrapResult, error) {
+	if r.stream != nil {
+		return BootstrapResult{}, ErrNoTrainingData
+	}
+	if r.errorCov != nil {
+		return BootstrapResult{}, ErrUnsupportedFit
+	}
+	if level <= 0 || level >= 1 {
+		return BootstrapResult{}, ErrInvalidProbability
+	}
+	if samples < 2 {
+		return BootstrapResult{}, ErrNotEnoughData
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	coeffs := make([][]float64, samples)
+	r2 := make([]float64, samples)
+	errs := make([]error, samples)
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range indices {
+				rnd := rand.New(rand.NewSource(seed + int64(s)))
+				resampled := make([]DataPoint, len(r.Data))
+				for i := range resampled {
+					resampled[i] = r.Data[rnd.Intn(len(r.Data))]
+				}
+				model := r.withData(resampled)
+				errs[s] = model.Run()
+				coeffs[s], r2[s] = model.GetCoeffs(), model.R2
+			}
+		}()
+	}
+	for s := 0; s < samples; s++ {
+		indices <- s
+	}
+	close(indices)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return BootstrapResult{}, err
+		}
+	}
+
+	lowerQ, upperQ := (1-level)/2, (1+level)/2
+	p := len(coeffs[0])
+	result := BootstrapResult{CoeffLower: make([]float64, p), CoeffUpper: make([]float64, p)}
+	estimates := make([]float64, samples)
+	for j := 0; j < p; j++ {
+		for s := range coeffs {
+			estimates[s] = coeffs[s][j]
+		}
+		sort.Float64s(estimates)
+		result.CoeffLower[j], result.CoeffUpper[j] = quantile(estimates, lowerQ), quantile(estimates, upperQ)
+	}
+	sort.Float64s(r2)
+	result.R2Lower, result.R2Upper = quantile(r2, lowerQ), quantile(r2, upperQ)
+	return result, nil
+}
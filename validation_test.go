@@ -0,0 +1,203 @@
+package regression
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestCrossValidate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := &Regression{}
+	for i := 0; i < 100; i++ {
+		x := rnd.Float64() * 10
+		r.Train(DataPoint{Observed: 1 + 2*x + 0.5*x*x + rnd.NormFloat64(), Variables: []float64{x}})
+	}
+	r.AddCross(PowCross(0, 2))
+
+	result, err := CrossValidate(r, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.R2) != 5 || len(result.RMSE) != 5 || len(result.MAE) != 5 {
+		t.Fatalf("Expected metrics for 5 folds, got %+v", result)
+	}
+	if result.R2Mean < 0.95 || math.Abs(result.RMSEMean-1) > 0.3 || result.MAEMean > result.RMSEMean {
+		t.Errorf("Expected a good out of sample fit with a noise of 1, got %+v", result)
+	}
+	if result.RMSEStd <= 0 {
+		t.Errorf("Expected the folds to differ, got %+v", result)
+	}
+	if r.Ready || len(r.Data) != 100 {
+		t.Errorf("Expected the regression to be left untouched")
+	}
+	if again, _ := CrossValidate(r, 5, 1); again.RMSEMean != result.RMSEMean {
+		t.Errorf("Expected the same folds with the same seed, got RMSE %v and %v", result.RMSEMean, again.RMSEMean)
+	}
+
+	// without the shuffle, sorted data points would make folds of narrow ranges
+	sorted := &Regression{}
+	sorted.AddCross(PowCross(0, 2))
+	data := append(DataPoints(nil), r.Data...)
+	sort.Slice(data, func(i, j int) bool { return data[i].Variables[0] < data[j].Variables[0] })
+	sorted.Train(data...)
+	shuffled, err := CrossValidate(sorted, 5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shuffled.R2Mean < 0.95 {
+		t.Errorf("Expected shuffled folds to cover the range of the sorted data points, got %+v", shuffled)
+	}
+
+	// without the cross, the quadratic term is missed
+	linear := &Regression{}
+	linear.Train(r.Data...)
+	worse, err := CrossValidate(linear, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worse.RMSEMean <= result.RMSEMean {
+		t.Errorf("Expected a higher error without the cross, got %v and %v", worse.RMSEMean, result.RMSEMean)
+	}
+
+	if _, err := CrossValidate(r, 1, 1); err != ErrInvalidFolds {
+		t.Errorf("Expected %v, got %v", ErrInvalidFolds, err)
+	}
+}
+
+func TestLOOCV(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.LOOCV(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		x, y := rnd.Float64()*10, rnd.Float64()
+		r.Train(DataPoint{Observed: 1 + 2*x - 3*y + rnd.NormFloat64(), Variables: []float64{x, y}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := r.LOOCV()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the shortcut matches refitting without each data point
+	for i, d := range r.Data {
+		train := append(append([]DataPoint(nil), r.Data[:i]...), r.Data[i+1:]...)
+		model := r.withData(train)
+		if err := model.Run(); err != nil {
+			t.Fatal(err)
+		}
+		predicted, _ := model.Predict(d.Variables)
+		if math.Abs(result.Residuals[i]-(d.Observed-predicted)) > 1e-9 {
+			t.Errorf("Expected leave-one-out residual %v for data point %d, got %v", d.Observed-predicted, i, result.Residuals[i])
+		}
+	}
+	press, _ := r.PRESS()
+	predictedR2, _ := r.PredictedR2()
+	if math.Abs(result.PRESS-press) > 1e-9 || math.Abs(result.PredictedR2-predictedR2) > 1e-12 {
+		t.Errorf("Expected PRESS %v and predicted R2 %v, got %+v", press, predictedR2, result)
+	}
+	if math.Abs(result.RMSE-math.Sqrt(press/20)) > 1e-12 {
+		t.Errorf("Expected RMSE %v, got %v", math.Sqrt(press/20), result.RMSE)
+	}
+
+	r.SetRegularization(L2, 1)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.LOOCV(); err != ErrUnsupportedFit {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedFit, err)
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := &Regression{}
+	for i := 0; i < 100; i++ {
+		x := rnd.Float64() * 10
+		r.Train(DataPoint{Observed: 1 + 2*x + rnd.NormFloat64(), Variables: []float64{x}})
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Bootstrap(r, 500, 0.95, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, c := range r.GetCoeffs() {
+		if result.CoeffLower[j] >= c || result.CoeffUpper[j] <= c {
+			t.Errorf("Expected the interval of coefficient %d to contain %v, got [%v, %v]", j, c, result.CoeffLower[j], result.CoeffUpper[j])
+		}
+	}
+	// the bootstrap interval of the slope is close to the classical one, ±1.98 standard errors
+	stdErrors, err := r.stdErrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width := result.CoeffUpper[1] - result.CoeffLower[1]; math.Abs(width-2*1.98*stdErrors[1]) > 0.3*width {
+		t.Errorf("Expected an interval width close to %v, got %v", 2*1.98*stdErrors[1], width)
+	}
+	if result.R2Lower >= r.R2 || result.R2Upper <= r.R2 {
+		t.Errorf("Expected the interval of R2 to contain %v, got [%v, %v]", r.R2, result.R2Lower, result.R2Upper)
+	}
+
+	sequential, err := Bootstrap(r, 500, 0.95, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sequential.CoeffLower[1] != result.CoeffLower[1] || sequential.R2Upper != result.R2Upper {
+		t.Errorf("Expected the result not to depend on the number of workers, got %+v and %+v", sequential, result)
+	}
+
+	if _, err := Bootstrap(r, 500, 1.5, 1, 1); err != ErrInvalidProbability {
+		t.Errorf("Expected %v, got %v", ErrInvalidProbability, err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	r := &Regression{}
+	if _, err := r.Metrics(); err != ErrRegressionRun {
+		t.Errorf("Expected %v, got %v", ErrRegressionRun, err)
+	}
+	r.Train(
+		DataPoint{Observed: 2, Variables: []float64{1}},
+		DataPoint{Observed: 5, Variables: []float64{2}},
+		DataPoint{Observed: 5, Variables: []float64{3}},
+		DataPoint{Observed: 8, Variables: []float64{4}},
+	)
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fit is 0.5 + 1.8x, with residuals -0.3, 0.9, -0.9 and 0.3
+	m, err := r.Metrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := math.Sqrt(0.45); math.Abs(m.RMSE-expected) > 1e-9 {
+		t.Errorf("Expected RMSE %v, got %v", expected, m.RMSE)
+	}
+	if math.Abs(m.MAE-0.6) > 1e-9 {
+		t.Errorf("Expected MAE 0.6, got %v", m.MAE)
+	}
+	if expected := 25 * (0.3/2 + 0.9/5 + 0.9/5 + 0.3/8); math.Abs(m.MAPE-expected) > 1e-9 {
+		t.Errorf("Expected MAPE %v, got %v", expected, m.MAPE)
+	}
+
+	held, err := r.Evaluate([]DataPoint{{Observed: 10, Variables: []float64{5}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(held.MAE-0.5) > 1e-9 || math.Abs(held.RMSE-0.5) > 1e-9 || math.Abs(held.MAPE-5) > 1e-9 {
+		t.Errorf("Expected errors of 0.5, 0.5 and 5%%, got %+v", held)
+	}
+
+	if _, err := r.Evaluate(nil); err != ErrNotEnoughData {
+		t.Errorf("Expected %v, got %v", ErrNotEnoughData, err)
+	}
+}